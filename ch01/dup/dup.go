@@ -0,0 +1,726 @@
+// Dup prints each line that appears at least twice in its input, with its
+// count. It reads the named files, or the standard input if there are none;
+// "-" also names the standard input. Lines are reported most frequent first,
+// and lines with the same count in lexical order. Lines may be of any length.
+// If an input cannot be read, dup reports it, counts the rest, and exits
+// with status 1.
+//
+// With -external, dup finds duplicates by sorting its input on disk rather
+// than counting lines in memory, so it can handle inputs much larger than
+// memory. It sorts about -mem bytes of lines at a time, at least 1 MiB, writes
+// each sorted run to a file in a temporary directory in $TMPDIR, and merges
+// the runs. With -n it writes where each line occurs there too. Its output is
+// the same. The directory is removed when dup exits, including when it is
+// interrupted or its output is closed.
+//
+// Usage:
+//
+//	dup [-f] [-n] [-min n] [-trim] [-i] [-squash] [-external [-mem bytes]] [file ...]
+package main
+
+import (
+	"bufio"
+	"container/heap"
+	"encoding/binary"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+
+	"gopl/ch01/linenorm"
+)
+
+var showFiles bool
+
+var (
+	showLines = flag.Bool("n", false, "show the file and line number of each occurrence")
+	minCount  = flag.Int("min", 2, "report lines that appear at least `n` times")
+	norm      = linenorm.Flags(flag.CommandLine)
+	external  = flag.Bool("external", false, "sort lines on disk, for inputs larger than memory")
+	mem       = flag.Int("mem", 64<<20, "with -external, sort about `bytes` of lines in memory at a time")
+)
+
+// minMem is the least -mem accepted.
+const minMem = 1 << 20
+
+func init() {
+	const usage = "show where duplicates appear"
+	flag.BoolVar(&showFiles, "files", false, usage)
+	flag.BoolVar(&showFiles, "f", false, usage+" (shorthand)")
+}
+
+// A pos is where a line occurs.
+type pos struct {
+	file string
+	line int
+}
+
+// An entry records the lines that are the same once normalized.
+type entry struct {
+	text  string   // the line as first seen
+	count int      // number of occurrences
+	files []string // files it occurs in, in the order first seen
+	lines []pos    // where it occurs, if -n is set and it is counted in memory
+	at    int64    // where its places start in dupExternal's places file
+}
+
+// stdin is what "-" names; tests replace it.
+var stdin io.Reader = os.Stdin
+
+func main() {
+	flag.Parse()
+	if *mem < minMem {
+		fmt.Fprintf(os.Stderr, "dup: -mem must be at least %d\n", minMem)
+		flag.Usage()
+		os.Exit(2)
+	}
+	files := flag.Args()
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	var err error
+	if *external {
+		err = dupExternal(out, files)
+	} else {
+		err = dup(out, files)
+	}
+	if flushErr := out.Flush(); flushErr != nil && (err == nil || err == errInput) {
+		err = flushErr
+	}
+	switch {
+	case err == errInput:
+		os.Exit(1) // each input error has been reported
+	case err != nil:
+		fmt.Fprintf(os.Stderr, "dup: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// errInput is returned once the duplicates have been printed if some input
+// could not be read. What could be read is counted.
+var errInput = errors.New("some input could not be read")
+
+// dup prints the duplicate lines in the named files to w, counting them in
+// memory.
+func dup(w io.Writer, files []string) error {
+	counts := make(map[string]*entry)
+	err := eachLine(files, func(_ int, name string, n int, l string) error {
+		key := norm.Normalize(l)
+		e := counts[key]
+		if e == nil {
+			e = &entry{text: l}
+			counts[key] = e
+		}
+		e.add(name)
+		if *showLines {
+			e.lines = append(e.lines, pos{name, n})
+		}
+		return nil
+	})
+	for _, e := range sorted(counts) {
+		printEntry(w, e)
+	}
+	return err
+}
+
+// A lineFunc is called with each line of input, along with the index of its
+// file among the arguments, the name to report the file under, and the line
+// number.
+type lineFunc func(file int, name string, n int, l string) error
+
+// eachLine calls f with each line of the named files, where "-" is the
+// standard input. A file that cannot be opened or read is reported and
+// skipped, or the rest of it is, and eachLine goes on to the next; it then
+// returns errInput at the end. eachLine stops at the first error from f and
+// returns it.
+func eachLine(files []string, f lineFunc) error {
+	var err error
+	for i, arg := range files {
+		var readErr, fErr error
+		if arg == "-" {
+			readErr, fErr = readLines(stdin, "stdin", i, f)
+			if readErr != nil {
+				readErr = fmt.Errorf("stdin: %v", readErr)
+			}
+		} else if fh, openErr := os.Open(arg); openErr != nil {
+			readErr = openErr
+		} else {
+			readErr, fErr = readLines(fh, arg, i, f)
+			fh.Close()
+			if readErr != nil {
+				readErr = fmt.Errorf("%s: %v", arg, readErr)
+			}
+		}
+		if fErr != nil {
+			return fErr
+		}
+		if readErr != nil {
+			fmt.Fprintf(os.Stderr, "dup: %v\n", readErr)
+			err = errInput
+		}
+	}
+	return err
+}
+
+// readLines calls f with each line of r, without its line ending, however
+// long the line is. It returns the error that stopped the reading of r, if
+// any, separately from an error from f, which stops it too.
+func readLines(r io.Reader, name string, file int, f lineFunc) (readErr, fErr error) {
+	in := bufio.NewReader(r)
+	for n := 1; ; n++ {
+		l, err := in.ReadString('\n')
+		if len(l) > 0 {
+			l = strings.TrimSuffix(l, "\n")
+			if fErr := f(file, name, n, strings.TrimSuffix(l, "\r")); fErr != nil {
+				return nil, fErr
+			}
+		}
+		if err == io.EOF {
+			return nil, nil
+		}
+		if err != nil {
+			return err, nil
+		}
+	}
+}
+
+// add records an occurrence of e in the named file.
+func (e *entry) add(name string) {
+	e.count++
+	if len(e.files) == 0 || e.files[len(e.files)-1] != name {
+		e.files = append(e.files, name)
+	}
+}
+
+func printEntry(w io.Writer, e *entry) {
+	if showFiles {
+		fmt.Fprintf(w, "[%s] in %s: %d times\n", e.text, strings.Join(e.files, ", "), e.count)
+	} else {
+		fmt.Fprintf(w, "[%s] appears %d times\n", e.text, e.count)
+	}
+	for _, p := range e.lines {
+		fmt.Fprintf(w, "\t%s:%d\n", p.file, p.line)
+	}
+}
+
+// sorted returns the entries that occur at least -min times, most frequent
+// first and then in lexical order.
+func sorted(counts map[string]*entry) []*entry {
+	var dups []*entry
+	for _, e := range counts {
+		if e.count >= *minCount {
+			dups = append(dups, e)
+		}
+	}
+	sort.Slice(dups, func(i, j int) bool { return before(dups[i], dups[j]) })
+	return dups
+}
+
+// before reports whether a is reported before b: if it is more frequent, or
+// as frequent and lexically first.
+func before(a, b *entry) bool {
+	if a.count != b.count {
+		return a.count > b.count
+	}
+	return a.text < b.text
+}
+
+// An occurrence is one line of input, as dupExternal sorts it.
+type occurrence struct {
+	key  string // the normalized line
+	text string
+	file int // index of the file among the arguments
+	line int
+}
+
+// dupExternal prints the duplicate lines in the named files to w without holding
+// more than about -mem bytes of them in memory. It sorts every line by its
+// normalized form, so that equal lines end up next to each other and can be
+// counted one group at a time, and then sorts the duplicates by count. Both
+// sorts spill to a temporary directory. With -n the places each duplicate
+// occurs are written to a file there too, and read back as it is printed, so
+// a line that occurs very often costs no more memory than one that doesn't.
+func dupExternal(w io.Writer, files []string) error {
+	dir, err := os.MkdirTemp("", "dup-")
+	if err != nil {
+		return err
+	}
+	defer removeOnSignal(dir)()
+	defer os.RemoveAll(dir)
+
+	lines := &sorter[occurrence]{
+		less: func(a, b occurrence) bool {
+			if a.key != b.key {
+				return a.key < b.key
+			}
+			if a.file != b.file {
+				return a.file < b.file
+			}
+			return a.line < b.line
+		},
+		size: func(o occurrence) int {
+			n := len(o.key) + 64
+			if o.text != o.key {
+				n += len(o.text)
+			}
+			return n
+		},
+		write: writeOccurrence,
+		read:  readOccurrence,
+		limit: *mem,
+		dir:   dir,
+	}
+	dups := &sorter[*entry]{
+		less: before,
+		size: func(e *entry) int {
+			n := len(e.text) + 64
+			for _, f := range e.files {
+				n += len(f) + 16
+			}
+			return n
+		},
+		write: writeEntry,
+		read:  readEntry,
+		limit: *mem,
+		dir:   dir,
+	}
+	var p *places
+	if *showLines {
+		if p, err = newPlaces(dir); err != nil {
+			return err
+		}
+		defer p.f.Close()
+	}
+
+	names := make([]string, len(files))
+	inputErr := eachLine(files, func(i int, name string, n int, l string) error {
+		names[i] = name
+		return lines.add(occurrence{norm.Normalize(l), l, i, n})
+	})
+	if inputErr != nil && inputErr != errInput {
+		return inputErr
+	}
+
+	var e *entry
+	var key string
+	flush := func() error {
+		if e != nil && e.count >= *minCount {
+			return dups.add(e)
+		}
+		return nil
+	}
+	err = lines.merge(func(o occurrence) error {
+		if e == nil || o.key != key {
+			if err := flush(); err != nil {
+				return err
+			}
+			e, key = &entry{text: o.text}, o.key
+			if p != nil {
+				e.at = p.start()
+			}
+		}
+		e.add(names[o.file])
+		if p != nil {
+			p.add(e.count, o.file, o.line)
+		}
+		return nil
+	})
+	if err == nil {
+		err = flush()
+	}
+	if err == nil && p != nil {
+		err = p.w.w.Flush()
+	}
+	if err != nil {
+		return err
+	}
+
+	err = dups.merge(func(e *entry) error {
+		printEntry(w, e)
+		if p != nil {
+			return p.print(w, e, names)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return inputErr
+}
+
+// removeOnSignal arranges for dir to be removed, and dup to exit, if it is
+// interrupted or terminated or writes to a closed pipe, until the function
+// it returns is called. Asking for SIGPIPE also makes writes to a closed
+// standard output fail with EPIPE rather than kill dup outright.
+func removeOnSignal(dir string) (stop func()) {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM, syscall.SIGPIPE)
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-c:
+			os.RemoveAll(dir)
+			os.Exit(1)
+		case <-done:
+		}
+	}()
+	return func() {
+		signal.Stop(c)
+		close(done)
+	}
+}
+
+// A place is where an occurrence is, by the index of its file among the
+// arguments and its line number.
+type place struct {
+	file, line int
+}
+
+// places is the file dupExternal writes the places of duplicate lines to
+// for -n, so that it need not hold them in memory. The places of each line
+// are written together, in order, once it has occurred -min times; until
+// then they are held in pending, and they are dropped if it never does.
+type places struct {
+	f       *os.File
+	w       *encoder
+	r       *bufio.Reader
+	pending []place
+}
+
+func newPlaces(dir string) (*places, error) {
+	f, err := os.Create(filepath.Join(dir, "places"))
+	if err != nil {
+		return nil, err
+	}
+	return &places{f: f, w: &encoder{w: bufio.NewWriter(f)}}, nil
+}
+
+// start begins the places of another line and returns the offset they will
+// be written at.
+func (p *places) start() int64 {
+	p.pending = p.pending[:0]
+	return p.w.n
+}
+
+// add records that the current line occurs at line of the file with index
+// file, which makes count occurrences of it so far.
+func (p *places) add(count, file, line int) {
+	if count < *minCount {
+		p.pending = append(p.pending, place{file, line})
+		return
+	}
+	for _, q := range p.pending {
+		p.w.int(q.file)
+		p.w.int(q.line)
+	}
+	p.pending = p.pending[:0]
+	p.w.int(file)
+	p.w.int(line)
+}
+
+// print prints the places of e, once everything has been written and
+// flushed, naming the files by names.
+func (p *places) print(w io.Writer, e *entry, names []string) error {
+	sr := io.NewSectionReader(p.f, e.at, 1<<62)
+	if p.r == nil {
+		p.r = bufio.NewReader(sr)
+	} else {
+		p.r.Reset(sr)
+	}
+	r := &decoder{r: p.r}
+	for i := 0; i < e.count; i++ {
+		file, line := r.int(), r.int()
+		if r.err == nil && file >= len(names) {
+			r.err = fmt.Errorf("corrupt places: file index %d out of range", file)
+		}
+		if r.err != nil {
+			return fmt.Errorf("%s: %v", p.f.Name(), r.end())
+		}
+		fmt.Fprintf(w, "\t%s:%d\n", names[file], line)
+	}
+	return nil
+}
+
+func writeOccurrence(w *encoder, o occurrence) {
+	w.string(o.key)
+	if o.text == o.key {
+		w.int(0)
+	} else {
+		w.int(1)
+		w.string(o.text)
+	}
+	w.int(o.file)
+	w.int(o.line)
+}
+
+func readOccurrence(r *decoder) (occurrence, error) {
+	var o occurrence
+	o.key = r.string()
+	if r.err != nil {
+		return o, r.err // io.EOF at the end of the run
+	}
+	o.text = o.key
+	if r.int() != 0 {
+		o.text = r.string()
+	}
+	o.file = r.int()
+	o.line = r.int()
+	return o, r.end()
+}
+
+// writeEntry writes e, with the offset of its places rather than the places
+// themselves.
+func writeEntry(w *encoder, e *entry) {
+	w.string(e.text)
+	w.int(e.count)
+	w.int(len(e.files))
+	for _, f := range e.files {
+		w.string(f)
+	}
+	w.int(int(e.at))
+}
+
+func readEntry(r *decoder) (*entry, error) {
+	e := &entry{text: r.string()}
+	if r.err != nil {
+		return nil, r.err // io.EOF at the end of the run
+	}
+	e.count = r.int()
+	for n := r.int(); n > 0 && r.err == nil; n-- {
+		e.files = append(e.files, r.string())
+	}
+	e.at = int64(r.int())
+	return e, r.end()
+}
+
+// maxRuns is the most runs a sorter merges at once, which bounds the files it
+// has open. If there are more, they are merged in batches first.
+const maxRuns = 64
+
+// A sorter sorts more items than fit in memory. It collects items until
+// their size reaches limit, sorts them and writes them to a file in dir as a
+// run. merge then reads the runs back together, in order. Removing dir is
+// left to the caller.
+type sorter[T any] struct {
+	less  func(a, b T) bool
+	size  func(T) int
+	write func(*encoder, T)
+	read  func(*decoder) (T, error) // returns io.EOF at the end of a run
+	limit int
+	dir   string
+
+	buf  []T
+	used int
+	runs []string // names of the run files
+}
+
+func (s *sorter[T]) add(t T) error {
+	s.buf = append(s.buf, t)
+	s.used += s.size(t)
+	if s.used < s.limit {
+		return nil
+	}
+	return s.spill()
+}
+
+func (s *sorter[T]) sort() {
+	sort.Slice(s.buf, func(i, j int) bool { return s.less(s.buf[i], s.buf[j]) })
+}
+
+// spill writes the items in memory to a new run.
+func (s *sorter[T]) spill() error {
+	s.sort()
+	err := s.newRun(func(w *encoder) error {
+		for _, t := range s.buf {
+			s.write(w, t)
+		}
+		return nil
+	})
+	s.buf, s.used = nil, 0
+	return err
+}
+
+// newRun creates a run and calls fill to write its items.
+func (s *sorter[T]) newRun(fill func(w *encoder) error) error {
+	f, err := os.CreateTemp(s.dir, "run-")
+	if err != nil {
+		return err
+	}
+	s.runs = append(s.runs, f.Name())
+	w := &encoder{w: bufio.NewWriter(f)}
+	err = fill(w)
+	if err == nil {
+		err = w.w.Flush()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// merge calls f with each item added, in order. If they all fit in memory,
+// no run is written, and each item is let go once f has it, so that what f
+// keeps and what is left to merge take about limit bytes between them.
+func (s *sorter[T]) merge(f func(T) error) error {
+	if len(s.runs) == 0 {
+		s.sort()
+		buf := s.buf
+		s.buf, s.used = nil, 0
+		var zero T
+		for i, t := range buf {
+			buf[i] = zero
+			if err := f(t); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if len(s.buf) > 0 {
+		if err := s.spill(); err != nil {
+			return err
+		}
+	}
+	for len(s.runs) > maxRuns {
+		batch := s.runs[:maxRuns]
+		s.runs = s.runs[maxRuns:]
+		err := s.newRun(func(w *encoder) error {
+			return s.mergeRuns(batch, func(t T) error {
+				s.write(w, t)
+				return nil
+			})
+		})
+		removeAll(batch)
+		if err != nil {
+			return err
+		}
+	}
+	return s.mergeRuns(s.runs, f)
+}
+
+// mergeRuns calls f with the items of the named runs, in order, keeping the
+// next item of each run in a heap.
+func (s *sorter[T]) mergeRuns(runs []string, f func(T) error) error {
+	h := &mergeHeap[T]{less: s.less}
+	for _, name := range runs {
+		fh, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer fh.Close()
+		r := &decoder{r: bufio.NewReader(fh)}
+		t, err := s.read(r)
+		if err == io.EOF {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+		h.heads = append(h.heads, t)
+		h.runs = append(h.runs, r)
+	}
+	heap.Init(h)
+	for h.Len() > 0 {
+		if err := f(h.heads[0]); err != nil {
+			return err
+		}
+		t, err := s.read(h.runs[0])
+		switch {
+		case err == io.EOF:
+			heap.Pop(h)
+		case err != nil:
+			return err
+		default:
+			h.heads[0] = t
+			heap.Fix(h, 0)
+		}
+	}
+	return nil
+}
+
+func removeAll(names []string) {
+	for _, name := range names {
+		os.Remove(name)
+	}
+}
+
+// A mergeHeap holds the next item of each run being merged, least first.
+type mergeHeap[T any] struct {
+	heads []T
+	runs  []*decoder
+	less  func(a, b T) bool
+}
+
+func (h *mergeHeap[T]) Len() int           { return len(h.heads) }
+func (h *mergeHeap[T]) Less(i, j int) bool { return h.less(h.heads[i], h.heads[j]) }
+func (h *mergeHeap[T]) Swap(i, j int) {
+	h.heads[i], h.heads[j] = h.heads[j], h.heads[i]
+	h.runs[i], h.runs[j] = h.runs[j], h.runs[i]
+}
+func (h *mergeHeap[T]) Push(x interface{}) { panic("mergeHeap: Push") }
+func (h *mergeHeap[T]) Pop() interface{} {
+	n := len(h.heads) - 1
+	t := h.heads[n]
+	h.heads, h.runs = h.heads[:n], h.runs[:n]
+	return t
+}
+
+// An encoder writes the fields of items to a run as varints and
+// length-prefixed strings. Errors are caught when the run is flushed.
+type encoder struct {
+	w   *bufio.Writer
+	n   int64 // bytes written
+	buf [binary.MaxVarintLen64]byte
+}
+
+func (e *encoder) int(n int) {
+	m, _ := e.w.Write(e.buf[:binary.PutUvarint(e.buf[:], uint64(n))])
+	e.n += int64(m)
+}
+
+func (e *encoder) string(s string) {
+	e.int(len(s))
+	m, _ := e.w.WriteString(s)
+	e.n += int64(m)
+}
+
+// A decoder reads what an encoder wrote, remembering the first error.
+type decoder struct {
+	r   *bufio.Reader
+	err error
+}
+
+func (d *decoder) int() int {
+	if d.err != nil {
+		return 0
+	}
+	n, err := binary.ReadUvarint(d.r)
+	d.err = err
+	return int(n)
+}
+
+func (d *decoder) string() string {
+	n := d.int()
+	if d.err != nil {
+		return ""
+	}
+	b := make([]byte, n)
+	_, d.err = io.ReadFull(d.r, b)
+	return string(b)
+}
+
+// end returns the error from reading an item after its first field, when the
+// end of the run means the item was cut short.
+func (d *decoder) end() error {
+	if d.err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return d.err
+}
@@ -0,0 +1,315 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setFlags resets dup's flags to their defaults, then parses args. The
+// defaults are restored when t ends.
+func setFlags(t *testing.T, args ...string) {
+	t.Helper()
+	reset := func() {
+		flag.VisitAll(func(f *flag.Flag) {
+			if !strings.HasPrefix(f.Name, "test.") {
+				f.Value.Set(f.DefValue)
+			}
+		})
+	}
+	reset()
+	t.Cleanup(reset)
+	if err := flag.CommandLine.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// writeFiles writes files, a map from name to contents, to a temporary
+// directory and returns the paths of names there. "-" is left as it is.
+func writeFiles(t *testing.T, files map[string]string, names ...string) (dir string, paths []string) {
+	t.Helper()
+	dir = t.TempDir()
+	for name, text := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(text), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, name := range names {
+		if name != "-" {
+			name = filepath.Join(dir, name)
+		}
+		paths = append(paths, name)
+	}
+	return dir, paths
+}
+
+// run runs dup on names and returns its output with the temporary directory
+// removed from file names.
+func run(t *testing.T, files map[string]string, names ...string) string {
+	t.Helper()
+	dir, paths := writeFiles(t, files, names...)
+	var out strings.Builder
+	if err := dup(&out, paths); err != nil {
+		t.Fatalf("dup: %v", err)
+	}
+	return strings.ReplaceAll(out.String(), dir+string(filepath.Separator), "")
+}
+
+var testFiles = map[string]string{
+	"a": "b\na\nb\na\nc\nc\nc\n",
+	"b": "b\nx\n",
+	"c": "Hello  World\n  hello world\nHELLO\tWORLD\n",
+}
+
+func TestDup(t *testing.T) {
+	tests := map[string]struct {
+		args  []string
+		names []string
+		want  string
+	}{
+		"by count, then text": {
+			names: []string{"a"},
+			want:  "[c] appears 3 times\n[a] appears 2 times\n[b] appears 2 times\n",
+		},
+		"counts span files": {
+			names: []string{"b", "a"},
+			want:  "[b] appears 3 times\n[c] appears 3 times\n[a] appears 2 times\n",
+		},
+		"min": {
+			args:  []string{"-min", "3"},
+			names: []string{"a", "b"},
+			want:  "[b] appears 3 times\n[c] appears 3 times\n",
+		},
+		"min 1 keeps single lines": {
+			args:  []string{"-min", "1"},
+			names: []string{"b"},
+			want:  "[b] appears 1 times\n[x] appears 1 times\n",
+		},
+		"files": {
+			args:  []string{"-f", "-min", "3"},
+			names: []string{"b", "a"},
+			want:  "[b] in b, a: 3 times\n[c] in a: 3 times\n",
+		},
+		"line numbers": {
+			args:  []string{"-n", "-min", "3"},
+			names: []string{"a", "b"},
+			want:  "[b] appears 3 times\n\ta:1\n\ta:3\n\tb:1\n[c] appears 3 times\n\ta:5\n\ta:6\n\ta:7\n",
+		},
+		"no normalization": {
+			names: []string{"c"},
+			want:  "",
+		},
+		"fold alone": {
+			args:  []string{"-i"},
+			names: []string{"c"},
+			want:  "",
+		},
+		"squash and fold": {
+			args:  []string{"-squash", "-i"},
+			names: []string{"c"},
+			want:  "[Hello  World] appears 2 times\n",
+		},
+		"trim, squash and fold": {
+			args:  []string{"-trim", "-squash", "-i"},
+			names: []string{"c"},
+			want:  "[Hello  World] appears 3 times\n",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			setFlags(t, tc.args...)
+			if got := run(t, testFiles, tc.names...); got != tc.want {
+				t.Errorf("got %q; want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDupStdin(t *testing.T) {
+	setFlags(t, "-n")
+	old := stdin
+	stdin = strings.NewReader("x\ny\nx\n")
+	defer func() { stdin = old }()
+
+	want := "[x] appears 2 times\n\tstdin:1\n\tstdin:3\n"
+	if got := run(t, nil, "-"); got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
+
+// runExternal runs dupExternal on names like run, with $TMPDIR set to a
+// directory of its own, and checks that nothing is left there.
+func runExternal(t *testing.T, files map[string]string, names ...string) string {
+	t.Helper()
+	dir, paths := writeFiles(t, files, names...)
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+	var out strings.Builder
+	if err := dupExternal(&out, paths); err != nil {
+		t.Fatalf("dupExternal: %v", err)
+	}
+	if left, err := os.ReadDir(tmp); err != nil || len(left) > 0 {
+		t.Errorf("$TMPDIR holds %d entries afterwards (%v)", len(left), err)
+	}
+	return strings.ReplaceAll(out.String(), dir+string(filepath.Separator), "")
+}
+
+// bigFiles returns inputs with many repeated lines in several forms, and
+// lines that occur once among them.
+func bigFiles() map[string]string {
+	forms := []string{"alpha", "Alpha", " alpha ", "beta  gamma", "Beta\tgamma", "delta"}
+	var big1, big2 strings.Builder
+	for i := 0; i < 300; i++ {
+		if i%2 == 0 {
+			big1.WriteString("hot\n")
+		}
+		fmt.Fprintf(&big1, "%s\n", forms[i%len(forms)])
+		if i%5 == 0 {
+			fmt.Fprintf(&big1, "once %d\n", i)
+		}
+		fmt.Fprintf(&big2, "%d\n%s\n", i%13, forms[i%4])
+	}
+	files := map[string]string{"big1": big1.String(), "big2": big2.String()}
+	for name, text := range testFiles {
+		files[name] = text
+	}
+	return files
+}
+
+func TestDupExternalMatchesDup(t *testing.T) {
+	files := bigFiles()
+	names := []string{"a", "big1", "b", "big2", "c"}
+	flags := map[string][]string{
+		"default":              nil,
+		"lines":                {"-n"},
+		"files":                {"-f"},
+		"normalized":           {"-i", "-trim", "-squash"},
+		"min 3":                {"-min", "3"},
+		"min 1":                {"-min", "1"},
+		"lines and files":      {"-n", "-f", "-min", "50"},
+		"lines and normalized": {"-n", "-i", "-trim", "-squash", "-min", "1"},
+	}
+	// A -mem of 1 writes each item to a run of its own, which is more runs
+	// than are merged at once.
+	mems := []string{"1", "100", "4096", "1048576"}
+
+	for name, args := range flags {
+		for _, mem := range mems {
+			t.Run(name+" mem "+mem, func(t *testing.T) {
+				setFlags(t, append([]string{"-mem", mem}, args...)...)
+				want := run(t, files, names...)
+				if want == "" {
+					t.Fatal("dup found no duplicates")
+				}
+				if got := runExternal(t, files, names...); got != want {
+					t.Errorf("dupExternal differs from dup\ngot:\n%s\nwant:\n%s", got, want)
+				}
+			})
+		}
+	}
+}
+
+func TestDupExternalStdin(t *testing.T) {
+	setFlags(t, "-n", "-mem", "1")
+	old := stdin
+	stdin = strings.NewReader("x\ny\nx\n")
+	defer func() { stdin = old }()
+
+	want := "[x] appears 2 times\n\tstdin:1\n\tstdin:3\n"
+	if got := runExternal(t, nil, "-"); got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
+
+func TestSorterMergesBatches(t *testing.T) {
+	dir := t.TempDir()
+	s := &sorter[int]{
+		less:  func(a, b int) bool { return a < b },
+		size:  func(int) int { return 1 },
+		write: func(w *encoder, n int) { w.int(n) },
+		read: func(r *decoder) (int, error) {
+			n := r.int()
+			return n, r.err
+		},
+		limit: 3,
+		dir:   dir,
+	}
+	// With 3 items to a run, this makes over three times as many runs as are
+	// merged at once, so merge has to merge several batches first.
+	const n = 9*maxRuns + 7
+	for _, v := range rand.New(rand.NewSource(1)).Perm(n) {
+		if err := s.add(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(s.runs) <= 2*maxRuns {
+		t.Fatalf("%d runs; want more than %d", len(s.runs), 2*maxRuns)
+	}
+
+	var got []int
+	err := s.merge(func(v int) error {
+		got = append(got, v)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != n {
+		t.Fatalf("merged %d items; want %d", len(got), n)
+	}
+	for i, v := range got {
+		if v != i {
+			t.Fatalf("item %d is %d", i, v)
+		}
+	}
+	if len(s.runs) > maxRuns {
+		t.Errorf("%d runs left to merge at once; want at most %d", len(s.runs), maxRuns)
+	}
+	if left, _ := os.ReadDir(dir); len(left) != len(s.runs) {
+		t.Errorf("%d run files left; want %d", len(left), len(s.runs))
+	}
+}
+
+func TestDupLongLines(t *testing.T) {
+	long := strings.Repeat("x", 70000)
+	files := map[string]string{
+		"long": long + "\nafter\n" + long + "\r\nafter",
+	}
+	want := "[after] appears 2 times\n[" + long + "] appears 2 times\n"
+
+	for _, mem := range []string{"1", "1048576"} {
+		setFlags(t, "-mem", mem)
+		if got := run(t, files, "long"); got != want {
+			t.Errorf("dup: got %.40q...; want %.40q...", got, want)
+		}
+		if got := runExternal(t, files, "long"); got != want {
+			t.Errorf("dupExternal, -mem %s: got %.40q...; want %.40q...", mem, got, want)
+		}
+	}
+}
+
+func TestDupUnreadableFile(t *testing.T) {
+	setFlags(t, "-mem", "1")
+	dir, paths := writeFiles(t, testFiles, "missing", "a")
+	t.Setenv("TMPDIR", t.TempDir())
+	want := "[c] appears 3 times\n[a] appears 2 times\n[b] appears 2 times\n"
+
+	for name, f := range map[string]func(io.Writer, []string) error{
+		"dup":         dup,
+		"dupExternal": dupExternal,
+	} {
+		var out strings.Builder
+		if err := f(&out, paths); err != errInput {
+			t.Errorf("%s: got error %v; want %v", name, err, errInput)
+		}
+		if got := strings.ReplaceAll(out.String(), dir+string(filepath.Separator), ""); got != want {
+			t.Errorf("%s: got %q; want %q", name, got, want)
+		}
+	}
+}
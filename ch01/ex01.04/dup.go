@@ -1,38 +1,128 @@
+// Dup prints each line that appears at least twice in one of its inputs,
+// with the file it appears in and its count there. It reads the named files,
+// or the standard input if there are none; "-" also names the standard input.
+// Lines are reported most frequent first, and lines with the same count in
+// order of file and then text.
+//
+// Usage:
+//
+//	dup [-n] [-min n] [-trim] [-i] [-squash] [file ...]
 package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
+	"io"
 	"os"
+	"sort"
+
+	"gopl/ch01/linenorm"
+)
+
+var (
+	showLines = flag.Bool("n", false, "show the line number of each occurrence")
+	minCount  = flag.Int("min", 2, "report lines that appear at least `n` times in a file")
+	norm      = linenorm.Flags(flag.CommandLine)
 )
 
+// A key identifies a line within a file, once normalized.
+type key struct {
+	fileName string
+	line     string
+}
+
+// An entry records the lines of one file that are the same once normalized.
+type entry struct {
+	fileName string
+	text     string // the line as first seen
+	count    int
+	lines    []int // line numbers, if -n is set
+}
+
+// stdin is what "-" names; tests replace it.
+var stdin io.Reader = os.Stdin
+
 func main() {
-	counts := make(map[string]int)
-	files := os.Args[1:]
+	flag.Parse()
+	files := flag.Args()
 	if len(files) == 0 {
-		countLines("stdin", os.Stdin, counts)
-	} else {
-		for _, fileName := range files {
-			fh, err := os.Open(fileName)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "dup: %v\n", err)
-				continue
-			}
-			countLines(fileName, fh, counts)
-			fh.Close()
+		files = []string{"-"}
+	}
+	out := bufio.NewWriter(os.Stdout)
+	dup(out, files)
+	if err := out.Flush(); err != nil {
+		fmt.Fprintf(os.Stderr, "dup: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// dup prints the lines repeated within each of the named files to w.
+func dup(w io.Writer, files []string) {
+	counts := make(map[key]*entry)
+	for _, fileName := range files {
+		if fileName == "-" {
+			countLines("stdin", stdin, counts)
+			continue
+		}
+		fh, err := os.Open(fileName)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "dup: %v\n", err)
+			continue
+		}
+		countLines(fileName, fh, counts)
+		fh.Close()
+	}
+	report(w, counts)
+}
+
+// report prints the entries that occur at least -min times to w.
+func report(w io.Writer, counts map[key]*entry) {
+	for _, e := range sorted(counts) {
+		fmt.Fprintf(w, "%s: %q: %d\n", e.fileName, e.text, e.count)
+		for _, n := range e.lines {
+			fmt.Fprintf(w, "\t%s:%d\n", e.fileName, n)
 		}
 	}
-	for line, n := range counts {
-		if n > 1 {
-			fmt.Printf("%s: %d\n", line, n)
+}
+
+func countLines(fileName string, r io.Reader, counts map[key]*entry) {
+	input := bufio.NewScanner(r)
+	for n := 1; input.Scan(); n++ {
+		k := key{fileName, norm.Normalize(input.Text())}
+		e := counts[k]
+		if e == nil {
+			e = &entry{fileName: fileName, text: input.Text()}
+			counts[k] = e
+		}
+		e.count++
+		if *showLines {
+			e.lines = append(e.lines, n)
 		}
 	}
+	if err := input.Err(); err != nil {
+		fmt.Fprintf(os.Stderr, "dup: %s: %v\n", fileName, err)
+	}
 }
 
-func countLines(fileName string, fh *os.File, counts map[string]int) {
-	input := bufio.NewScanner(fh)
-	for input.Scan() {
-		nameAndLine := fmt.Sprintf("%s: %q", fileName, input.Text())
-		counts[nameAndLine]++
+// sorted returns the entries that occur at least -min times, most frequent
+// first and then by file name and text.
+func sorted(counts map[key]*entry) []*entry {
+	var dups []*entry
+	for _, e := range counts {
+		if e.count >= *minCount {
+			dups = append(dups, e)
+		}
 	}
+	sort.Slice(dups, func(i, j int) bool {
+		a, b := dups[i], dups[j]
+		if a.count != b.count {
+			return a.count > b.count
+		}
+		if a.fileName != b.fileName {
+			return a.fileName < b.fileName
+		}
+		return a.text < b.text
+	})
+	return dups
 }
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"gopl/ch01/linenorm"
+)
+
+// setOptions sets the flag variables for one test and restores them after it.
+func setOptions(t *testing.T, lines bool, n int, opts linenorm.Options) {
+	t.Helper()
+	oldLines, oldMin, oldNorm := *showLines, *minCount, *norm
+	*showLines, *minCount, *norm = lines, n, opts
+	t.Cleanup(func() {
+		*showLines, *minCount, *norm = oldLines, oldMin, oldNorm
+	})
+}
+
+// An input is the name and contents of a file.
+type input struct {
+	name, text string
+}
+
+// countAndReport counts the lines of inputs, in order, and returns the report.
+func countAndReport(inputs ...input) string {
+	counts := make(map[key]*entry)
+	for _, in := range inputs {
+		countLines(in.name, strings.NewReader(in.text), counts)
+	}
+	var out strings.Builder
+	report(&out, counts)
+	return out.String()
+}
+
+func TestReport(t *testing.T) {
+	var (
+		a = input{"a", "b\na\nb\na\nc\nc\nc\n"}
+		b = input{"b", "b\nx\n"}
+		c = input{"c", "Hello  World\n  hello world\nHELLO\tWORLD\n"}
+	)
+
+	tests := map[string]struct {
+		lines  bool
+		min    int
+		opts   linenorm.Options
+		inputs []input
+		want   string
+	}{
+		"by count, then file and text": {
+			min:    2,
+			inputs: []input{b, a},
+			want:   "a: \"c\": 3\na: \"a\": 2\na: \"b\": 2\n",
+		},
+		"counts are per file": {
+			min:    2,
+			inputs: []input{a, b},
+			want:   "a: \"c\": 3\na: \"a\": 2\na: \"b\": 2\n",
+		},
+		"min": {
+			min:    3,
+			inputs: []input{a},
+			want:   "a: \"c\": 3\n",
+		},
+		"min 1 keeps single lines": {
+			min:    1,
+			inputs: []input{b},
+			want:   "b: \"b\": 1\nb: \"x\": 1\n",
+		},
+		"line numbers": {
+			lines:  true,
+			min:    3,
+			inputs: []input{a},
+			want:   "a: \"c\": 3\n\ta:5\n\ta:6\n\ta:7\n",
+		},
+		"no normalization": {
+			min:    2,
+			inputs: []input{c},
+			want:   "",
+		},
+		"fold alone": {
+			min:    2,
+			opts:   linenorm.Options{Fold: true},
+			inputs: []input{c},
+			want:   "",
+		},
+		"squash and fold": {
+			min:    2,
+			opts:   linenorm.Options{Squash: true, Fold: true},
+			inputs: []input{c},
+			want:   "c: \"Hello  World\": 2\n",
+		},
+		"trim, squash and fold": {
+			min:    2,
+			opts:   linenorm.Options{Trim: true, Squash: true, Fold: true},
+			inputs: []input{c},
+			want:   "c: \"Hello  World\": 3\n",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			setOptions(t, tc.lines, tc.min, tc.opts)
+			if got := countAndReport(tc.inputs...); got != tc.want {
+				t.Errorf("got %q; want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDupStdin(t *testing.T) {
+	setOptions(t, true, 2, linenorm.Options{})
+	old := stdin
+	stdin = strings.NewReader("x\ny\nx\n")
+	defer func() { stdin = old }()
+
+	var out strings.Builder
+	dup(&out, []string{"-"})
+	want := "stdin: \"x\": 2\n\tstdin:1\n\tstdin:3\n"
+	if got := out.String(); got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
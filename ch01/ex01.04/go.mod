@@ -0,0 +1,7 @@
+module dup
+
+go 1.17
+
+require gopl/ch01/linenorm v0.0.0
+
+replace gopl/ch01/linenorm => ../linenorm
@@ -1,25 +1,47 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"time"
+
+	"gopl/ch03/units"
 )
 
+var limit units.Size
+
+func init() {
+	flag.Var(&limit, "limit", "stop reading each body after `size` (e.g. 500kB, 2MiB); 0 means no limit")
+}
+
 func main() {
-	for _, url := range os.Args[1:] {
+	flag.Parse()
+	for _, url := range flag.Args() {
+		start := time.Now()
 		resp, err := http.Get(url)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "fetch: %v\n", err)
+			continue
 		}
 
 		fmt.Printf("%s: %s\n", url, resp.Status)
-		bytes, err := io.Copy(os.Stdout, resp.Body)
+		var body io.Reader = resp.Body
+		if limit > 0 {
+			body = io.LimitReader(resp.Body, int64(limit))
+		}
+		bytes, err := io.Copy(os.Stdout, body)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "fetch: reading %s: %v\n", url, err)
+		}
 		err = resp.Body.Close()
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "fetch: %v\n", err)
 		}
-		fmt.Printf("%s: %d bytes\n", url, bytes)
+		elapsed := time.Since(start)
+		fmt.Printf("%s: %s in %s (%s)\n", url, units.FormatBytes(bytes, units.SI),
+			units.FormatDuration(elapsed), units.FormatRate(bytes, elapsed, units.SI))
 	}
 }
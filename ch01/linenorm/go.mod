@@ -0,0 +1,3 @@
+module gopl/ch01/linenorm
+
+go 1.17
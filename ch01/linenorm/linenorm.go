@@ -0,0 +1,79 @@
+// Package linenorm puts lines of text in a normal form before they are
+// compared, so that lines that differ only in case or spacing can count as
+// the same line. The dup commands use it for their -trim, -i and -squash
+// flags.
+package linenorm
+
+import (
+	"flag"
+	"strings"
+	"unicode"
+)
+
+// Options select what Normalize does to a line. The zero value leaves lines
+// as they are.
+type Options struct {
+	Trim   bool // drop leading and trailing white space
+	Squash bool // replace each run of white space with one space
+	Fold   bool // fold case, as strings.EqualFold does
+}
+
+// Flags defines the -trim, -i and -squash flags in fs and returns the Options
+// they set.
+func Flags(fs *flag.FlagSet) *Options {
+	o := new(Options)
+	fs.BoolVar(&o.Trim, "trim", false, "ignore leading and trailing space when comparing lines")
+	fs.BoolVar(&o.Fold, "i", false, "ignore case when comparing lines")
+	fs.BoolVar(&o.Squash, "squash", false, "treat each run of spaces as a single space when comparing lines")
+	return o
+}
+
+// Normalize returns the form of l that is compared with other lines.
+func (o Options) Normalize(l string) string {
+	if o.Trim {
+		l = strings.TrimSpace(l)
+	}
+	if o.Squash {
+		l = SquashSpace(l)
+	}
+	if o.Fold {
+		l = FoldCase(l)
+	}
+	return l
+}
+
+// SquashSpace replaces each run of Unicode white space in s with one space.
+func SquashSpace(s string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range s {
+		if unicode.IsSpace(r) {
+			space = true
+			continue
+		}
+		if space {
+			b.WriteByte(' ')
+			space = false
+		}
+		b.WriteRune(r)
+	}
+	if space {
+		b.WriteByte(' ')
+	}
+	return b.String()
+}
+
+// FoldCase maps each rune of s to the smallest rune it is equivalent to under
+// simple case folding, so that two strings fold to the same string exactly
+// when strings.EqualFold reports them equal.
+func FoldCase(s string) string {
+	return strings.Map(func(r rune) rune {
+		least := r
+		for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+			if f < least {
+				least = f
+			}
+		}
+		return least
+	}, s)
+}
@@ -0,0 +1,57 @@
+package linenorm_test
+
+import (
+	"flag"
+	"io"
+	"strings"
+	"testing"
+
+	"gopl/ch01/linenorm"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := map[string]struct {
+		opts linenorm.Options
+		in   string
+		want string
+	}{
+		"none":          {in: " A\t b ", want: " A\t b "},
+		"trim":          {opts: linenorm.Options{Trim: true}, in: " \tA b　", want: "A b"},
+		"squash":        {opts: linenorm.Options{Squash: true}, in: "  a \t\n b  ", want: " a b "},
+		"fold":          {opts: linenorm.Options{Fold: true}, in: "Straße ΣΑΣ", want: linenorm.FoldCase("STRAßE σας")},
+		"fold kelvin":   {opts: linenorm.Options{Fold: true}, in: "K", want: linenorm.FoldCase("k")},
+		"all three":     {opts: linenorm.Options{Trim: true, Squash: true, Fold: true}, in: "  Foo \t BAR  ", want: linenorm.FoldCase("foo bar")},
+		"invalid bytes": {opts: linenorm.Options{Squash: true, Fold: true}, in: "a\xff  b", want: linenorm.FoldCase("a� b")},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := tc.opts.Normalize(tc.in); got != tc.want {
+				t.Errorf("Normalize(%q) = %q; want %q", tc.in, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestFoldCaseMatchesEqualFold(t *testing.T) {
+	words := []string{"go", "GO", "Go", "straße", "STRASSE", "σας", "ΣΑΣ", "ΣΑς", "K", "k", "K", "ǅ", "ǆ", "Ǆ"}
+	for _, a := range words {
+		for _, b := range words {
+			if got, want := linenorm.FoldCase(a) == linenorm.FoldCase(b), strings.EqualFold(a, b); got != want {
+				t.Errorf("FoldCase(%q) == FoldCase(%q) is %t; EqualFold says %t", a, b, got, want)
+			}
+		}
+	}
+}
+
+func TestFlags(t *testing.T) {
+	fs := flag.NewFlagSet("dup", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	opts := linenorm.Flags(fs)
+	if err := fs.Parse([]string{"-i", "-squash"}); err != nil {
+		t.Fatal(err)
+	}
+	if want := (linenorm.Options{Squash: true, Fold: true}); *opts != want {
+		t.Errorf("got %+v; want %+v", *opts, want)
+	}
+}
@@ -5,6 +5,9 @@ import (
 	"strings"
 )
 
+// Commify inserts commas into s, a decimal number with an optional sign and
+// fractional part. It does not check that s is a number. For validation,
+// other locales and numeric types, see the numfmt package.
 func Commify(s string) string {
 	// Return empty string immediately.
 	if len(s) == 0 {
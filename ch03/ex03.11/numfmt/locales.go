@@ -0,0 +1,79 @@
+package numfmt
+
+// Separators that are easy to confuse with ordinary spaces and quotes.
+const (
+	nbsp       = "\u00a0" // no-break space
+	narrowNBSP = "\u202f" // narrow no-break space
+	apostrophe = "\u2019" // right single quotation mark, used in Switzerland
+	minusSign  = "\u2212" // minus sign
+)
+
+// Western is the grouping that ch03.Commify uses: "," every three digits
+// and "." as the decimal point.
+var Western = Locales["en-US"]
+
+// Locales maps BCP 47 tags to locales. The separators and currency patterns
+// follow the Unicode CLDR data for each locale.
+var Locales = map[string]Locale{
+	"en-US": {
+		Tag: "en-US", Group: ",", Decimal: ".", Primary: 3, Secondary: 3,
+		Minus: "-", Plus: "+", Exponent: "E",
+		Currency: "¤#", NegCurrency: "-¤#",
+	},
+	"en-GB": {
+		Tag: "en-GB", Group: ",", Decimal: ".", Primary: 3, Secondary: 3,
+		Minus: "-", Plus: "+", Exponent: "E",
+		Currency: "¤#", NegCurrency: "-¤#",
+	},
+	"en-IN": {
+		Tag: "en-IN", Group: ",", Decimal: ".", Primary: 3, Secondary: 2,
+		Minus: "-", Plus: "+", Exponent: "E",
+		Currency: "¤#", NegCurrency: "-¤#",
+	},
+	"hi-IN": {
+		Tag: "hi-IN", Group: ",", Decimal: ".", Primary: 3, Secondary: 2,
+		Minus: "-", Plus: "+", Exponent: "E",
+		Currency: "¤#", NegCurrency: "-¤#",
+	},
+	"de-DE": {
+		Tag: "de-DE", Group: ".", Decimal: ",", Primary: 3, Secondary: 3,
+		Minus: "-", Plus: "+", Exponent: "E",
+		Currency: "#" + nbsp + "¤", NegCurrency: "-#" + nbsp + "¤",
+	},
+	"it-IT": {
+		Tag: "it-IT", Group: ".", Decimal: ",", Primary: 3, Secondary: 3,
+		Minus: "-", Plus: "+", Exponent: "E",
+		Currency: "#" + nbsp + "¤", NegCurrency: "-#" + nbsp + "¤",
+	},
+	"es-ES": {
+		Tag: "es-ES", Group: ".", Decimal: ",", Primary: 3, Secondary: 3, MinDigits: 5,
+		Minus: "-", Plus: "+", Exponent: "E",
+		Currency: "#" + nbsp + "¤", NegCurrency: "-#" + nbsp + "¤",
+	},
+	"fr-FR": {
+		Tag: "fr-FR", Group: narrowNBSP, Decimal: ",", Primary: 3, Secondary: 3,
+		Minus: "-", Plus: "+", Exponent: "E",
+		Currency: "#" + narrowNBSP + "¤", NegCurrency: "-#" + narrowNBSP + "¤",
+	},
+	"de-CH": {
+		Tag: "de-CH", Group: apostrophe, Decimal: ".", Primary: 3, Secondary: 3,
+		Minus: "-", Plus: "+", Exponent: "E",
+		Currency: "¤" + nbsp + "#", NegCurrency: "¤-#",
+	},
+	"sv-SE": {
+		Tag: "sv-SE", Group: nbsp, Decimal: ",", Primary: 3, Secondary: 3,
+		Minus: minusSign, Plus: "+", Exponent: "×10^",
+		Currency: "#" + nbsp + "¤", NegCurrency: "-#" + nbsp + "¤",
+	},
+	"ja-JP": {
+		Tag: "ja-JP", Group: ",", Decimal: ".", Primary: 3, Secondary: 3,
+		Minus: "-", Plus: "+", Exponent: "E",
+		Currency: "¤#", NegCurrency: "-¤#",
+	},
+}
+
+// Lookup returns the locale for tag and reports whether it is known.
+func Lookup(tag string) (Locale, bool) {
+	l, ok := Locales[tag]
+	return l, ok
+}
@@ -0,0 +1,201 @@
+// Package numfmt formats decimal numbers for different locales. It grows out
+// of ch03.Commify, which only knows Western grouping with "," and ".".
+// A Locale describes digit grouping, separators, signs, exponents and where
+// a currency symbol goes; Locales holds a table of common ones.
+package numfmt
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A Locale describes how numbers are written in some region.
+type Locale struct {
+	Tag       string // BCP 47 tag, e.g. "en-US"
+	Group     string // separator between groups of integer digits
+	Decimal   string // separator between integer and fractional digits
+	Primary   int    // size of the group nearest the decimal separator
+	Secondary int    // size of every other group (2 for lakh/crore grouping)
+	MinDigits int    // integers with fewer digits than this are not grouped
+	Minus     string // sign for negative numbers
+	Plus      string // sign written when the input has an explicit "+"
+	Exponent  string // marker between a mantissa and its exponent
+
+	// Currency and NegCurrency are patterns for currency amounts. In each,
+	// "¤" stands for the currency symbol and "#" for the formatted number
+	// without its sign. A "-" or "+" in the pattern is replaced by the
+	// locale's sign.
+	Currency    string
+	NegCurrency string
+}
+
+// An Error describes a number that could not be formatted or parsed.
+type Error struct {
+	Input string // the text that was rejected
+	Pos   int    // byte offset of the problem in Input
+	Msg   string // description of the problem
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("numfmt: %s at offset %d in %q", e.Msg, e.Pos, e.Input)
+}
+
+// A number holds the parts of a plain decimal number.
+type number struct {
+	sign     byte   // '-', '+' or 0
+	integer  string // integer digits
+	fraction string // fractional digits, or "" if there is no decimal point
+	point    bool   // whether there is a decimal point
+	exponent string // exponent digits with any sign, or "" if there is none
+}
+
+// split breaks s into a number. s must have the form [+-]digits[.digits][e[+-]digits]
+// or [+-].digits[e[+-]digits], as strconv.FormatFloat and big.Int.String
+// produce it.
+func split(s string) (number, error) {
+	var n number
+	i := 0
+	if i < len(s) && (s[i] == '-' || s[i] == '+') {
+		n.sign = s[i]
+		i++
+	}
+
+	start := i
+	for i < len(s) && isDigit(s[i]) {
+		i++
+	}
+	n.integer = s[start:i]
+
+	if i < len(s) && s[i] == '.' {
+		n.point = true
+		i++
+		start = i
+		for i < len(s) && isDigit(s[i]) {
+			i++
+		}
+		n.fraction = s[start:i]
+	}
+	if n.integer == "" && n.fraction == "" {
+		return n, &Error{s, i, "expected a digit"}
+	}
+
+	if i < len(s) && (s[i] == 'e' || s[i] == 'E') {
+		i++
+		start = i
+		if i < len(s) && (s[i] == '-' || s[i] == '+') {
+			i++
+		}
+		digits := i
+		for i < len(s) && isDigit(s[i]) {
+			i++
+		}
+		if i == digits {
+			return n, &Error{s, i, "expected an exponent digit"}
+		}
+		n.exponent = s[start:i]
+	}
+
+	if i < len(s) {
+		return n, &Error{s, i, fmt.Sprintf("unexpected %q", s[i])}
+	}
+	return n, nil
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+// Format formats s, a plain decimal number such as "-1234567.89" or
+// "6.02214076e23", according to l. Only the integer digits are grouped.
+// Format returns an *Error if s is not a number.
+func (l Locale) Format(s string) (string, error) {
+	n, err := split(s)
+	if err != nil {
+		return "", err
+	}
+	return l.sign(n.sign) + l.unsigned(n), nil
+}
+
+// FormatCurrency formats s like Format and places symbol around it according
+// to the locale's currency patterns.
+func (l Locale) FormatCurrency(s, symbol string) (string, error) {
+	n, err := split(s)
+	if err != nil {
+		return "", err
+	}
+	pattern := l.Currency
+	if n.sign == '-' {
+		pattern = l.NegCurrency
+	}
+	r := strings.NewReplacer(
+		"¤", symbol,
+		"#", l.unsigned(n),
+		"-", l.Minus,
+		"+", l.Plus,
+	)
+	out := r.Replace(pattern)
+	if n.sign == '+' && !strings.Contains(pattern, "+") {
+		out = l.Plus + out
+	}
+	return out, nil
+}
+
+func (l Locale) sign(c byte) string {
+	switch c {
+	case '-':
+		return l.Minus
+	case '+':
+		return l.Plus
+	}
+	return ""
+}
+
+// unsigned formats n without its sign.
+func (l Locale) unsigned(n number) string {
+	var b strings.Builder
+	b.WriteString(l.group(n.integer))
+	if n.point {
+		b.WriteString(l.Decimal)
+		b.WriteString(n.fraction)
+	}
+	if n.exponent != "" {
+		b.WriteString(l.Exponent)
+		b.WriteString(strings.Replace(n.exponent, "-", l.Minus, 1))
+	}
+	return b.String()
+}
+
+// group inserts l.Group between groups of digits. The group nearest the end
+// has l.Primary digits and the others have l.Secondary digits.
+func (l Locale) group(digits string) string {
+	if l.Primary <= 0 || len(digits) < l.MinDigits || len(digits) <= l.Primary {
+		return digits
+	}
+	secondary := l.Secondary
+	if secondary <= 0 {
+		secondary = l.Primary
+	}
+
+	// Collect groups from the right, then write them out from the left.
+	var groups []string
+	end := len(digits)
+	size := l.Primary
+	for end > 0 {
+		start := end - size
+		if start < 0 {
+			start = 0
+		}
+		groups = append(groups, digits[start:end])
+		end = start
+		size = secondary
+	}
+
+	var b strings.Builder
+	for i := len(groups) - 1; i >= 0; i-- {
+		b.WriteString(groups[i])
+		if i > 0 {
+			b.WriteString(l.Group)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,109 @@
+package numfmt_test
+
+import (
+	"errors"
+	"gopl/ch03/numfmt"
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	tests := map[string]struct {
+		locale string
+		num    string
+		want   string
+	}{
+		"western short":        {locale: "en-US", num: "123", want: "123"},
+		"western":              {locale: "en-US", num: "1234567.89", want: "1,234,567.89"},
+		"western signed":       {locale: "en-US", num: "-1234567", want: "-1,234,567"},
+		"western plus":         {locale: "en-US", num: "+1234", want: "+1,234"},
+		"western no integer":   {locale: "en-US", num: ".5", want: ".5"},
+		"western exponent":     {locale: "en-US", num: "6.02214076e23", want: "6.02214076E23"},
+		"western big mantissa": {locale: "en-US", num: "12345e-3", want: "12,345E-3"},
+		"indian thousand":      {locale: "en-IN", num: "1234", want: "1,234"},
+		"indian lakh":          {locale: "en-IN", num: "123456", want: "1,23,456"},
+		"indian crore":         {locale: "en-IN", num: "123456789", want: "12,34,56,789"},
+		"indian fraction":      {locale: "hi-IN", num: "-10000000.5", want: "-1,00,00,000.5"},
+		"german":               {locale: "de-DE", num: "1234567.89", want: "1.234.567,89"},
+		"italian":              {locale: "it-IT", num: "-1000", want: "-1.000"},
+		"spanish four digits":  {locale: "es-ES", num: "1234", want: "1234"},
+		"spanish five digits":  {locale: "es-ES", num: "12345", want: "12.345"},
+		"french":               {locale: "fr-FR", num: "1234567.89", want: "1\u202f234\u202f567,89"},
+		"swiss":                {locale: "de-CH", num: "1234567.89", want: "1’234’567.89"},
+		"swedish":              {locale: "sv-SE", num: "-1234.5", want: "\u22121\u00a0234,5"},
+		"swedish exponent":     {locale: "sv-SE", num: "1.5e-9", want: "1,5×10^\u22129"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			l, ok := numfmt.Lookup(tc.locale)
+			if !ok {
+				t.Fatalf("numfmt.Lookup(%q) failed", tc.locale)
+			}
+			got, err := l.Format(tc.num)
+			if err != nil {
+				t.Fatalf("%s.Format(%q) failed: %v", tc.locale, tc.num, err)
+			}
+			if got != tc.want {
+				t.Errorf("%s.Format(%q) = %q; want %q", tc.locale, tc.num, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestFormatCurrency(t *testing.T) {
+	tests := map[string]struct {
+		locale string
+		num    string
+		symbol string
+		want   string
+	}{
+		"dollars":          {locale: "en-US", num: "1234.5", symbol: "$", want: "$1,234.5"},
+		"negative dollars": {locale: "en-US", num: "-1234.5", symbol: "$", want: "-$1,234.5"},
+		"rupees":           {locale: "en-IN", num: "1234567", symbol: "₹", want: "₹12,34,567"},
+		"euros":            {locale: "de-DE", num: "-1234.56", symbol: "€", want: "-1.234,56\u00a0€"},
+		"french euros":     {locale: "fr-FR", num: "1234.56", symbol: "€", want: "1\u202f234,56\u202f€"},
+		"francs":           {locale: "de-CH", num: "1234.5", symbol: "CHF", want: "CHF\u00a01’234.5"},
+		"negative francs":  {locale: "de-CH", num: "-1234.5", symbol: "CHF", want: "CHF-1’234.5"},
+		"explicit plus":    {locale: "en-US", num: "+5", symbol: "$", want: "+$5"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := numfmt.Locales[tc.locale].FormatCurrency(tc.num, tc.symbol)
+			if err != nil {
+				t.Fatalf("%s.FormatCurrency(%q) failed: %v", tc.locale, tc.num, err)
+			}
+			if got != tc.want {
+				t.Errorf("%s.FormatCurrency(%q, %q) = %q; want %q", tc.locale, tc.num, tc.symbol, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestFormatError(t *testing.T) {
+	tests := map[string]struct {
+		num string
+		pos int
+	}{
+		"empty string":     {num: "", pos: 0},
+		"sign only":        {num: "-", pos: 1},
+		"letters":          {num: "abc", pos: 0},
+		"commas":           {num: "1,234", pos: 1},
+		"two points":       {num: "1.2.3", pos: 3},
+		"empty exponent":   {num: "1e", pos: 2},
+		"trailing garbage": {num: "12x", pos: 2},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := numfmt.Western.Format(tc.num)
+			var e *numfmt.Error
+			if !errors.As(err, &e) {
+				t.Fatalf("Format(%q) error = %v; want *numfmt.Error", tc.num, err)
+			}
+			if e.Pos != tc.pos {
+				t.Errorf("Format(%q) error at %d; want %d", tc.num, e.Pos, tc.pos)
+			}
+		})
+	}
+}
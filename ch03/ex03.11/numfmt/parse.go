@@ -0,0 +1,224 @@
+package numfmt
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// A Number is a number parsed from locale-formatted text. Its methods convert
+// it to Go numeric types.
+type Number struct {
+	input string // the text that was parsed
+	plain string // the same number as [-]digits[.digits][e[-]digits]
+}
+
+// String returns the number in plain form, e.g. "-1234567.89", which
+// strconv and math/big accept.
+func (n Number) String() string {
+	return n.plain
+}
+
+// Parse parses s, a number written according to l, such as "1,234,567.89"
+// for en-US or "12,34,567" for en-IN. Group separators are optional, but
+// where they appear, every group must have the size the locale requires:
+// "1,23,4" is rejected for both locales. Besides the locale's own signs,
+// separators and exponent marker, Parse accepts ASCII "-", "+", "e" and "E",
+// plain spaces where the locale groups with a no-break space, and "'" where
+// it groups with "’". Parse returns an *Error giving the byte offset of the
+// first problem in s.
+func Parse(s string, l Locale) (Number, error) {
+	p := parser{input: s, locale: l}
+	if err := p.parse(); err != nil {
+		return Number{}, err
+	}
+	return Number{input: s, plain: p.out.String()}, nil
+}
+
+type parser struct {
+	input  string
+	locale Locale
+	pos    int
+	out    strings.Builder
+}
+
+func (p *parser) errorf(pos int, format string, args ...interface{}) error {
+	return &Error{Input: p.input, Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// accept consumes the first of alts found at the current position and
+// reports whether there was one.
+func (p *parser) accept(alts ...string) bool {
+	for _, a := range alts {
+		if a != "" && strings.HasPrefix(p.input[p.pos:], a) {
+			p.pos += len(a)
+			return true
+		}
+	}
+	return false
+}
+
+// digits consumes a run of ASCII digits and returns it.
+func (p *parser) digits() string {
+	start := p.pos
+	for p.pos < len(p.input) && isDigit(p.input[p.pos]) {
+		p.pos++
+	}
+	return p.input[start:p.pos]
+}
+
+func (p *parser) parse() error {
+	l := p.locale
+	if p.accept(l.Minus, "-") {
+		p.out.WriteByte('-')
+	} else {
+		p.accept(l.Plus, "+")
+	}
+
+	hasInt, err := p.integer()
+	if err != nil {
+		return err
+	}
+
+	if p.accept(l.Decimal) {
+		p.out.WriteByte('.')
+		frac := p.digits()
+		if frac == "" && !hasInt {
+			return p.errorf(p.pos, "expected a digit")
+		}
+		p.out.WriteString(frac)
+	} else if !hasInt {
+		return p.errorf(p.pos, "expected a digit")
+	}
+
+	if p.accept(l.Exponent, "e", "E") {
+		p.out.WriteByte('e')
+		if p.accept(l.Minus, "-") {
+			p.out.WriteByte('-')
+		} else {
+			p.accept(l.Plus, "+")
+		}
+		exp := p.digits()
+		if exp == "" {
+			return p.errorf(p.pos, "expected an exponent digit")
+		}
+		p.out.WriteString(exp)
+	}
+
+	if p.pos < len(p.input) {
+		r, _ := utf8.DecodeRuneInString(p.input[p.pos:])
+		return p.errorf(p.pos, "unexpected %q", r)
+	}
+	return nil
+}
+
+// integer consumes the integer part, checking the size of each group. It
+// reports whether there were any integer digits.
+func (p *parser) integer() (bool, error) {
+	type group struct {
+		size int // number of digits
+		end  int // offset just past the last digit
+	}
+	var groups []group
+
+	for {
+		d := p.digits()
+		if d == "" {
+			if len(groups) > 0 {
+				return true, p.errorf(p.pos, "expected a digit after group separator")
+			}
+			return false, nil // no integer part, as in ".5"
+		}
+		p.out.WriteString(d)
+		groups = append(groups, group{len(d), p.pos})
+		if !p.accept(groupSeparators(p.locale.Group)...) {
+			break
+		}
+	}
+
+	primary := p.locale.Primary
+	secondary := p.locale.Secondary
+	if secondary <= 0 {
+		secondary = primary
+	}
+	for i, g := range groups {
+		switch {
+		case len(groups) == 1:
+			// Ungrouped digits are always acceptable.
+		case i == 0:
+			if g.size > secondary {
+				return true, p.errorf(g.end, "group of %d digits, want at most %d", g.size, secondary)
+			}
+		case i == len(groups)-1:
+			if g.size != primary {
+				return true, p.errorf(g.end, "group of %d digits, want %d", g.size, primary)
+			}
+		default:
+			if g.size != secondary {
+				return true, p.errorf(g.end, "group of %d digits, want %d", g.size, secondary)
+			}
+		}
+	}
+	return true, nil
+}
+
+// groupSeparators returns the separators accepted in place of sep.
+func groupSeparators(sep string) []string {
+	switch sep {
+	case nbsp, narrowNBSP, " ":
+		return []string{sep, nbsp, narrowNBSP, " "}
+	case apostrophe, "'":
+		return []string{apostrophe, "'"}
+	}
+	return []string{sep}
+}
+
+// Int64 returns n as an int64. It fails if n is not an integer or does not
+// fit in an int64. An exponent is allowed if the value is whole, so "1.5E3"
+// gives 1500.
+func (n Number) Int64() (int64, error) {
+	i, err := n.BigInt()
+	if err != nil {
+		return 0, err
+	}
+	if !i.IsInt64() {
+		return 0, &Error{Input: n.input, Pos: 0, Msg: "value out of range for int64"}
+	}
+	return i.Int64(), nil
+}
+
+// Float64 returns the float64 nearest to n. It fails if n is out of range.
+func (n Number) Float64() (float64, error) {
+	f, err := strconv.ParseFloat(n.plain, 64)
+	if err != nil {
+		return f, &Error{Input: n.input, Pos: 0, Msg: "value out of range for float64"}
+	}
+	return f, nil
+}
+
+// BigInt returns n as a *big.Int. It fails if n is not an integer, or if its
+// exponent is too large for the value to be worked out.
+func (n Number) BigInt() (*big.Int, error) {
+	r, ok := new(big.Rat).SetString(n.plain)
+	if !ok {
+		// n.plain is well formed, so big.Rat only refuses it for the size of
+		// its exponent.
+		return nil, &Error{Input: n.input, Pos: 0, Msg: "exponent out of range"}
+	}
+	if !r.IsInt() {
+		return nil, &Error{Input: n.input, Pos: 0, Msg: "not an integer"}
+	}
+	return r.Num(), nil
+}
+
+// BigFloat returns n as a *big.Float with the given precision in bits,
+// rounded to nearest even.
+func (n Number) BigFloat(prec uint) (*big.Float, error) {
+	f, _, err := big.ParseFloat(n.plain, 10, prec, big.ToNearestEven)
+	if err != nil {
+		return nil, &Error{Input: n.input, Pos: 0, Msg: err.Error()}
+	}
+	return f, nil
+}
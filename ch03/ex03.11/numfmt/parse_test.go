@@ -0,0 +1,127 @@
+package numfmt_test
+
+import (
+	"errors"
+	"gopl/ch03/numfmt"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := map[string]struct {
+		locale string
+		input  string
+		want   string
+	}{
+		"plain integer":         {locale: "en-US", input: "1234567", want: "1234567"},
+		"western":               {locale: "en-US", input: "1,234,567.89", want: "1234567.89"},
+		"western negative":      {locale: "en-US", input: "-1,234", want: "-1234"},
+		"western plus":          {locale: "en-US", input: "+1,234", want: "1234"},
+		"western small":         {locale: "en-US", input: "999", want: "999"},
+		"western fraction only": {locale: "en-US", input: ".5", want: ".5"},
+		"western exponent":      {locale: "en-US", input: "6.02E23", want: "6.02e23"},
+		"lowercase exponent":    {locale: "en-US", input: "1,500e-3", want: "1500e-3"},
+		"indian":                {locale: "en-IN", input: "12,34,56,789.5", want: "123456789.5"},
+		"indian lakh":           {locale: "en-IN", input: "1,00,000", want: "100000"},
+		"german":                {locale: "de-DE", input: "1.234.567,89", want: "1234567.89"},
+		"french narrow nbsp":    {locale: "fr-FR", input: "1\u202f234,5", want: "1234.5"},
+		"french plain space":    {locale: "fr-FR", input: "1 234 567", want: "1234567"},
+		"swiss apostrophe":      {locale: "de-CH", input: "1’234.5", want: "1234.5"},
+		"swiss ascii quote":     {locale: "de-CH", input: "1'234'567", want: "1234567"},
+		"swedish minus":         {locale: "sv-SE", input: "\u22121\u00a0234,5", want: "-1234.5"},
+		"swedish exponent":      {locale: "sv-SE", input: "1,5×10^\u22129", want: "1.5e-9"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			n, err := numfmt.Parse(tc.input, numfmt.Locales[tc.locale])
+			if err != nil {
+				t.Fatalf("Parse(%q, %s) failed: %v", tc.input, tc.locale, err)
+			}
+			if got := n.String(); got != tc.want {
+				t.Errorf("Parse(%q, %s) = %q; want %q", tc.input, tc.locale, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseError(t *testing.T) {
+	tests := map[string]struct {
+		locale string
+		input  string
+		pos    int
+	}{
+		"empty string":         {locale: "en-US", input: "", pos: 0},
+		"sign only":            {locale: "en-US", input: "-", pos: 1},
+		"short middle group":   {locale: "en-US", input: "1,23,4", pos: 4},
+		"short last group":     {locale: "en-US", input: "1,234,56", pos: 8},
+		"long last group":      {locale: "en-US", input: "1,2345", pos: 6},
+		"long first group":     {locale: "en-US", input: "1234,567", pos: 4},
+		"trailing separator":   {locale: "en-US", input: "1,", pos: 2},
+		"western lakh":         {locale: "en-US", input: "1,23,456", pos: 4},
+		"indian thousands":     {locale: "en-IN", input: "123,456", pos: 3},
+		"indian short":         {locale: "en-IN", input: "1,23,4", pos: 6},
+		"german decimal point": {locale: "de-DE", input: "1.5", pos: 3},
+		"letters":              {locale: "en-US", input: "12abc", pos: 2},
+		"empty exponent":       {locale: "en-US", input: "1e", pos: 2},
+		"two decimal points":   {locale: "en-US", input: "1.2.3", pos: 3},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := numfmt.Parse(tc.input, numfmt.Locales[tc.locale])
+			var e *numfmt.Error
+			if !errors.As(err, &e) {
+				t.Fatalf("Parse(%q, %s) error = %v; want *numfmt.Error", tc.input, tc.locale, err)
+			}
+			if e.Pos != tc.pos {
+				t.Errorf("Parse(%q, %s) error at %d (%v); want %d", tc.input, tc.locale, e.Pos, err, tc.pos)
+			}
+		})
+	}
+}
+
+func TestNumberConversions(t *testing.T) {
+	n, err := numfmt.Parse("12,34,56,789", numfmt.Locales["en-IN"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if i, err := n.Int64(); err != nil || i != 123456789 {
+		t.Errorf("Int64() = %d, %v; want 123456789", i, err)
+	}
+	if f, err := n.Float64(); err != nil || f != 123456789 {
+		t.Errorf("Float64() = %g, %v; want 123456789", f, err)
+	}
+
+	n, _ = numfmt.Parse("1.5E3", numfmt.Western)
+	if i, err := n.Int64(); err != nil || i != 1500 {
+		t.Errorf("Int64() of 1.5E3 = %d, %v; want 1500", i, err)
+	}
+
+	n, _ = numfmt.Parse("1.5", numfmt.Western)
+	if _, err := n.Int64(); err == nil {
+		t.Error("Int64() of 1.5 succeeded; want error")
+	}
+
+	big20 := "100,000,000,000,000,000,000"
+	n, _ = numfmt.Parse(big20, numfmt.Western)
+	if _, err := n.Int64(); err == nil {
+		t.Errorf("Int64() of %s succeeded; want error", big20)
+	}
+	want, _ := new(big.Int).SetString("100000000000000000000", 10)
+	if i, err := n.BigInt(); err != nil || i.Cmp(want) != 0 {
+		t.Errorf("BigInt() = %v, %v; want %v", i, err, want)
+	}
+
+	n, _ = numfmt.Parse("1E100000000", numfmt.Western)
+	if _, err := n.BigInt(); err == nil || !strings.Contains(err.Error(), "exponent out of range") {
+		t.Errorf("BigInt() of 1E100000000 = _, %v; want exponent out of range", err)
+	}
+
+	n, _ = numfmt.Parse("-1.234.567,125", numfmt.Locales["de-DE"])
+	f, err := n.BigFloat(64)
+	if err != nil || f.Text('f', 3) != "-1234567.125" {
+		t.Errorf("BigFloat(64) = %v, %v; want -1234567.125", f, err)
+	}
+}
@@ -0,0 +1,179 @@
+package numfmt
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"strconv"
+
+	"golang.org/x/exp/constraints"
+)
+
+// A Rounding selects how a value is rounded to a fixed number of fraction
+// digits.
+type Rounding int
+
+const (
+	HalfEven Rounding = iota // to nearest, ties to even (banker's rounding)
+	HalfUp                   // to nearest, ties away from zero
+	HalfDown                 // to nearest, ties toward zero
+	Down                     // toward zero (truncation)
+	Up                       // away from zero
+	Floor                    // toward negative infinity
+	Ceiling                  // toward positive infinity
+)
+
+// Shortest asks for as many fraction digits as a value needs: the shortest
+// text that reads back as the same float, or the exact decimal expansion of
+// a big.Rat.
+const Shortest = -1
+
+// Format formats x according to l with digits fraction digits, rounding with
+// mode. If digits is Shortest, floats use the fewest digits that represent
+// them exactly and integers get no fraction. Format returns an error for NaN
+// and infinities.
+func Format[T constraints.Integer | constraints.Float](l Locale, x T, digits int, mode Rounding) (string, error) {
+	one := T(1)
+	if one/2 != 0 { // T is a float type
+		f := float64(x)
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return "", &Error{Input: strconv.FormatFloat(f, 'g', -1, 64), Msg: "not a finite number"}
+		}
+		if digits == Shortest {
+			bits := 64
+			if p := float64(1<<24 + 1); float64(T(p)) != p { // only float32 loses it
+				bits = 32
+			}
+			return l.Format(strconv.FormatFloat(f, 'f', -1, bits))
+		}
+		return FormatRat(l, new(big.Rat).SetFloat64(f), digits, mode)
+	}
+
+	var i big.Int
+	if T(0)-one > 0 { // T is unsigned
+		i.SetUint64(uint64(x))
+	} else {
+		i.SetInt64(int64(x))
+	}
+	if digits == Shortest {
+		return FormatBigInt(l, &i), nil
+	}
+	return FormatRat(l, new(big.Rat).SetInt(&i), digits, mode)
+}
+
+// FormatBigInt formats x according to l.
+func FormatBigInt(l Locale, x *big.Int) string {
+	s, _ := l.Format(x.String()) // a big.Int always formats as a valid integer
+	return s
+}
+
+// FormatBigFloat formats x according to l with digits fraction digits,
+// rounding with mode. If digits is Shortest, x gets the fewest digits that
+// read back as x at its precision. FormatBigFloat returns an error for
+// infinities.
+func FormatBigFloat(l Locale, x *big.Float, digits int, mode Rounding) (string, error) {
+	if x.IsInf() {
+		return "", &Error{Input: x.String(), Msg: "not a finite number"}
+	}
+	if digits == Shortest {
+		return l.Format(x.Text('f', -1))
+	}
+	r, _ := x.Rat(nil) // exact for finite x
+	return FormatRat(l, r, digits, mode)
+}
+
+// FormatRat formats x according to l with digits fraction digits, rounding
+// with mode. If digits is Shortest, x is written exactly; FormatRat returns an
+// error if its decimal expansion does not terminate, as for 1/3.
+func FormatRat(l Locale, x *big.Rat, digits int, mode Rounding) (string, error) {
+	if digits == Shortest {
+		d, ok := terminatingDigits(x.Denom())
+		if !ok {
+			return "", &Error{Input: x.String(), Msg: "decimal expansion does not terminate"}
+		}
+		digits = d
+	}
+	if digits < 0 {
+		return "", errors.New("numfmt: negative number of fraction digits")
+	}
+	return l.Format(roundRat(x, digits, mode))
+}
+
+// roundRat returns x rounded to digits fraction digits as plain decimal text.
+func roundRat(x *big.Rat, digits int, mode Rounding) string {
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(digits)), nil)
+	num := new(big.Int).Mul(x.Num(), scale)
+	q, r := new(big.Int).QuoRem(num, x.Denom(), new(big.Int))
+	neg := x.Sign() < 0
+	q.Abs(q)
+
+	if r.Sign() != 0 {
+		// Compare twice the remainder with the denominator to place the
+		// discarded part below, at or above one half.
+		half := new(big.Int).Abs(r)
+		half.Lsh(half, 1)
+		cmp := half.Cmp(x.Denom())
+
+		var away bool
+		switch mode {
+		case HalfEven:
+			away = cmp > 0 || cmp == 0 && q.Bit(0) == 1
+		case HalfUp:
+			away = cmp >= 0
+		case HalfDown:
+			away = cmp > 0
+		case Down:
+			away = false
+		case Up:
+			away = true
+		case Floor:
+			away = neg
+		case Ceiling:
+			away = !neg
+		}
+		if away {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+
+	s := q.String()
+	if digits > 0 {
+		for len(s) <= digits {
+			s = "0" + s
+		}
+		s = s[:len(s)-digits] + "." + s[len(s)-digits:]
+	}
+	if neg && q.Sign() != 0 {
+		s = "-" + s
+	}
+	return s
+}
+
+// terminatingDigits reports how many fraction digits 1/d needs when written
+// in decimal, and whether that number is finite. It is finite exactly when
+// d has no prime factors other than 2 and 5.
+func terminatingDigits(d *big.Int) (int, bool) {
+	d = new(big.Int).Set(d)
+	var twos, fives int
+	for d.Bit(0) == 0 {
+		d.Rsh(d, 1)
+		twos++
+	}
+	five := big.NewInt(5)
+	q, r := new(big.Int), new(big.Int)
+	for {
+		q.QuoRem(d, five, r)
+		if r.Sign() != 0 {
+			break
+		}
+		d.Set(q)
+		fives++
+	}
+	if d.Cmp(big.NewInt(1)) != 0 {
+		return 0, false
+	}
+	if twos > fives {
+		return twos, true
+	}
+	return fives, true
+}
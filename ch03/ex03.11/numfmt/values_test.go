@@ -0,0 +1,160 @@
+package numfmt_test
+
+import (
+	"gopl/ch03/numfmt"
+	"math"
+	"math/big"
+	"testing"
+)
+
+// celsius is a named float type, as in ch02's tempconv.
+type celsius float32
+
+func TestFormatValues(t *testing.T) {
+	en := numfmt.Western
+	de := numfmt.Locales["de-DE"]
+	in := numfmt.Locales["en-IN"]
+
+	tests := map[string]struct {
+		got  func() (string, error)
+		want string
+	}{
+		"int": {
+			func() (string, error) { return numfmt.Format(en, 1234567, numfmt.Shortest, numfmt.HalfEven) },
+			"1,234,567",
+		},
+		"negative int8": {
+			func() (string, error) { return numfmt.Format(en, int8(-128), numfmt.Shortest, numfmt.HalfEven) },
+			"-128",
+		},
+		"max uint64": {
+			func() (string, error) {
+				return numfmt.Format(en, uint64(math.MaxUint64), numfmt.Shortest, numfmt.HalfEven)
+			},
+			"18,446,744,073,709,551,615",
+		},
+		"int with fixed digits": {
+			func() (string, error) { return numfmt.Format(de, 1234, 2, numfmt.HalfEven) },
+			"1.234,00",
+		},
+		"float shortest": {
+			func() (string, error) { return numfmt.Format(en, 1234567.25, numfmt.Shortest, numfmt.HalfEven) },
+			"1,234,567.25",
+		},
+		"float32 shortest": {
+			func() (string, error) { return numfmt.Format(en, float32(0.1), numfmt.Shortest, numfmt.HalfEven) },
+			"0.1",
+		},
+		"float fixed": {
+			func() (string, error) { return numfmt.Format(in, 1234567.891, 2, numfmt.HalfEven) },
+			"12,34,567.89",
+		},
+		"float to integer": {
+			func() (string, error) { return numfmt.Format(en, 2.5, 0, numfmt.HalfEven) },
+			"2",
+		},
+		"half up": {
+			func() (string, error) { return numfmt.Format(en, 2.5, 0, numfmt.HalfUp) },
+			"3",
+		},
+		"half down": {
+			func() (string, error) { return numfmt.Format(en, -2.5, 0, numfmt.HalfDown) },
+			"-2",
+		},
+		"down": {
+			func() (string, error) { return numfmt.Format(en, -1999.99, 0, numfmt.Down) },
+			"-1,999",
+		},
+		"up": {
+			func() (string, error) { return numfmt.Format(en, 1999.01, 0, numfmt.Up) },
+			"2,000",
+		},
+		"floor": {
+			func() (string, error) { return numfmt.Format(en, -0.001, 2, numfmt.Floor) },
+			"-0.01",
+		},
+		"ceiling": {
+			func() (string, error) { return numfmt.Format(en, -0.001, 2, numfmt.Ceiling) },
+			"0.00",
+		},
+		"exact binary value": {
+			// 0.125 is exact in binary, so this is a true tie.
+			func() (string, error) { return numfmt.Format(en, 0.125, 2, numfmt.HalfEven) },
+			"0.12",
+		},
+		"big int": {
+			func() (string, error) {
+				i, _ := new(big.Int).SetString("-123456789012345678901234567890", 10)
+				return numfmt.FormatBigInt(en, i), nil
+			},
+			"-123,456,789,012,345,678,901,234,567,890",
+		},
+		"named float32 shortest": {
+			func() (string, error) { return numfmt.Format(en, celsius(0.1), numfmt.Shortest, numfmt.HalfEven) },
+			"0.1",
+		},
+		"big float shortest": {
+			func() (string, error) {
+				f, _, _ := big.ParseFloat("1234567.0625", 10, 100, big.ToNearestEven)
+				return numfmt.FormatBigFloat(de, f, numfmt.Shortest, numfmt.HalfEven)
+			},
+			"1.234.567,0625",
+		},
+		"big float fixed": {
+			func() (string, error) {
+				f, _, _ := big.ParseFloat("-1234567.0625", 10, 100, big.ToNearestEven)
+				return numfmt.FormatBigFloat(in, f, 3, numfmt.HalfEven)
+			},
+			"-12,34,567.062",
+		},
+		"big float beyond float64": {
+			func() (string, error) {
+				f := new(big.Float).SetMantExp(big.NewFloat(1), 2000)
+				s, err := numfmt.FormatBigFloat(en, f, 0, numfmt.HalfEven)
+				return s[:10] + "…" + s[len(s)-4:], err
+			},
+			"114,813,06…,376",
+		},
+		"rat exact": {
+			func() (string, error) {
+				return numfmt.FormatRat(de, big.NewRat(12345678, 8), numfmt.Shortest, numfmt.HalfEven)
+			},
+			"1.543.209,75",
+		},
+		"rat fixed": {
+			func() (string, error) { return numfmt.FormatRat(en, big.NewRat(2000, 3), 3, numfmt.HalfEven) },
+			"666.667",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := tc.got()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("got %q; want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestFormatValuesError(t *testing.T) {
+	en := numfmt.Western
+	if _, err := numfmt.Format(en, math.NaN(), 2, numfmt.HalfEven); err == nil {
+		t.Error("Format(NaN) succeeded; want error")
+	}
+	if _, err := numfmt.Format(en, math.Inf(-1), numfmt.Shortest, numfmt.HalfEven); err == nil {
+		t.Error("Format(-Inf) succeeded; want error")
+	}
+	if _, err := numfmt.FormatRat(en, big.NewRat(1, 3), numfmt.Shortest, numfmt.HalfEven); err == nil {
+		t.Error("FormatRat(1/3, Shortest) succeeded; want error")
+	}
+	if _, err := numfmt.FormatBigFloat(en, new(big.Float).SetInf(false), 2, numfmt.HalfEven); err == nil {
+		t.Error("FormatBigFloat(+Inf) succeeded; want error")
+	}
+	if _, err := en.Format("abc,def"); err == nil {
+		t.Error(`Format("abc,def") succeeded; want error`)
+	}
+}
@@ -0,0 +1,173 @@
+// Package units formats byte counts, durations and transfer rates for
+// people, and parses sizes such as "1.5GiB" or "500 MB" back into bytes.
+package units
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A System selects the multiples used for byte counts.
+type System int
+
+const (
+	SI  System = iota // powers of 1000: kB, MB, GB...
+	IEC               // powers of 1024: KiB, MiB, GiB...
+)
+
+// prefixes holds the unit prefixes in order of size, starting at 1000 or 1024.
+const prefixes = "kMGTPE"
+
+func (s System) base() float64 {
+	if s == IEC {
+		return 1024
+	}
+	return 1000
+}
+
+// unit returns the name of the unit base**exp bytes in s, e.g. "MB" or "MiB".
+func (s System) unit(exp int) string {
+	if exp == 0 {
+		return "B"
+	}
+	p := prefixes[exp-1 : exp]
+	if s == IEC {
+		return strings.ToUpper(p) + "iB"
+	}
+	return p + "B"
+}
+
+// FormatBytes formats n bytes with the largest unit of s that keeps the value
+// at least 1, using one fraction digit: FormatBytes(1500000, SI) is "1.5 MB"
+// and FormatBytes(1500000, IEC) is "1.4 MiB". Counts below one kilobyte are
+// written exactly, as in "512 B".
+func FormatBytes(n int64, s System) string {
+	return formatBytes(float64(n), s)
+}
+
+func formatBytes(n float64, s System) string {
+	sign := ""
+	if n < 0 {
+		sign, n = "-", -n
+	}
+	base := s.base()
+	if n < base {
+		return fmt.Sprintf("%s%.0f B", sign, n)
+	}
+	exp := 0
+	for n >= base && exp < len(prefixes) {
+		n /= base
+		exp++
+	}
+	// Rounding to one digit can reach the next unit, as 999.96 kB does.
+	if math.Round(n*10)/10 >= base && exp < len(prefixes) {
+		n /= base
+		exp++
+	}
+	return fmt.Sprintf("%s%.1f %s", sign, n, s.unit(exp))
+}
+
+// FormatDuration formats d with a precision that suits its size: whole
+// seconds from a minute up, hundredths of a unit below that. For example,
+// "1h2m3s", "4.56s" or "12.35ms".
+func FormatDuration(d time.Duration) string {
+	abs := d
+	if abs < 0 {
+		abs = -abs
+	}
+	switch {
+	case abs >= time.Minute:
+		d = d.Round(time.Second)
+	case abs >= time.Second:
+		d = d.Round(10 * time.Millisecond)
+	case abs >= time.Millisecond:
+		d = d.Round(10 * time.Microsecond)
+	case abs >= time.Microsecond:
+		d = d.Round(10 * time.Nanosecond)
+	}
+	return d.String()
+}
+
+// FormatRate formats the rate of n bytes in d as bytes per second, e.g.
+// "2.5 MB/s". A d of zero or less is treated as one nanosecond.
+func FormatRate(n int64, d time.Duration, s System) string {
+	if d <= 0 {
+		d = time.Nanosecond
+	}
+	return formatBytes(float64(n)/d.Seconds(), s) + "/s"
+}
+
+// ParseBytes parses a size such as "42", "500MB", "1.5 GiB" or "64k" and
+// returns the number of bytes, rounded to the nearest byte. Prefixes without
+// "i" are decimal and prefixes with "i" are binary, so "1kB" is 1000 and
+// "1KiB" is 1024. Prefix letters and the final "B" may be in either case.
+func ParseBytes(str string) (int64, error) {
+	s := strings.TrimSpace(str)
+	i := len(s)
+	for i > 0 && strings.IndexByte("0123456789.", s[i-1]) < 0 {
+		i--
+	}
+	num, unit := strings.TrimSpace(s[:i]), strings.TrimSpace(s[i:])
+	if num == "" {
+		return 0, fmt.Errorf("units: invalid size %q: missing number", str)
+	}
+	x, err := strconv.ParseFloat(num, 64)
+	if err != nil || x < 0 {
+		return 0, fmt.Errorf("units: invalid size %q: bad number %q", str, num)
+	}
+
+	mult, err := multiplier(unit)
+	if err != nil {
+		return 0, fmt.Errorf("units: invalid size %q: %v", str, err)
+	}
+	x = math.Round(x * mult)
+	if x >= math.MaxInt64 {
+		return 0, fmt.Errorf("units: invalid size %q: too large", str)
+	}
+	return int64(x), nil
+}
+
+// multiplier returns the number of bytes in unit, which may be empty.
+func multiplier(unit string) (float64, error) {
+	u := strings.ToLower(unit)
+	u = strings.TrimSuffix(u, "b")
+	if u == "" {
+		return 1, nil
+	}
+	s := SI
+	if strings.HasSuffix(u, "i") {
+		s = IEC
+		u = strings.TrimSuffix(u, "i")
+	}
+	if len(u) != 1 {
+		return 0, errors.New("unknown unit " + strconv.Quote(unit))
+	}
+	exp := strings.Index(strings.ToLower(prefixes), u)
+	if exp < 0 {
+		return 0, errors.New("unknown unit " + strconv.Quote(unit))
+	}
+	return math.Pow(s.base(), float64(exp+1)), nil
+}
+
+// A Size is a byte count that can be used as a flag.Value, so that a flag
+// such as -limit accepts "500MB" or "2GiB".
+type Size int64
+
+// String formats z in SI units.
+func (z *Size) String() string {
+	return FormatBytes(int64(*z), SI)
+}
+
+// Set parses s with ParseBytes.
+func (z *Size) Set(s string) error {
+	n, err := ParseBytes(s)
+	if err != nil {
+		return err
+	}
+	*z = Size(n)
+	return nil
+}
@@ -0,0 +1,119 @@
+package units_test
+
+import (
+	"flag"
+	"gopl/ch03/units"
+	"testing"
+	"time"
+)
+
+func TestFormatBytes(t *testing.T) {
+	tests := map[string]struct {
+		n    int64
+		sys  units.System
+		want string
+	}{
+		"zero":             {n: 0, sys: units.SI, want: "0 B"},
+		"bytes":            {n: 512, sys: units.SI, want: "512 B"},
+		"iec bytes":        {n: 1023, sys: units.IEC, want: "1023 B"},
+		"kilobytes":        {n: 1500, sys: units.SI, want: "1.5 kB"},
+		"kibibytes":        {n: 1536, sys: units.IEC, want: "1.5 KiB"},
+		"megabytes":        {n: 1500000, sys: units.SI, want: "1.5 MB"},
+		"mebibytes":        {n: 1500000, sys: units.IEC, want: "1.4 MiB"},
+		"gigabytes":        {n: 2e9, sys: units.SI, want: "2.0 GB"},
+		"rounds up a unit": {n: 999960, sys: units.SI, want: "1.0 MB"},
+		"exabytes":         {n: 9e18, sys: units.SI, want: "9.0 EB"},
+		"negative":         {n: -2048, sys: units.IEC, want: "-2.0 KiB"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := units.FormatBytes(tc.n, tc.sys); got != tc.want {
+				t.Errorf("FormatBytes(%d, %v) = %q; want %q", tc.n, tc.sys, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := map[string]struct {
+		d    time.Duration
+		want string
+	}{
+		"nanoseconds":  {d: 999, want: "999ns"},
+		"microseconds": {d: 1234567, want: "1.23ms"},
+		"seconds":      {d: 4567891234, want: "4.57s"},
+		"minutes":      {d: 150*time.Second + 400*time.Millisecond, want: "2m30s"},
+		"hours":        {d: time.Hour + 2*time.Minute + 3*time.Second, want: "1h2m3s"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := units.FormatDuration(tc.d); got != tc.want {
+				t.Errorf("FormatDuration(%d) = %q; want %q", tc.d, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestFormatRate(t *testing.T) {
+	if got := units.FormatRate(5000000, 2*time.Second, units.SI); got != "2.5 MB/s" {
+		t.Errorf("FormatRate(5000000, 2s, SI) = %q; want %q", got, "2.5 MB/s")
+	}
+	if got := units.FormatRate(3072, time.Second, units.IEC); got != "3.0 KiB/s" {
+		t.Errorf("FormatRate(3072, 1s, IEC) = %q; want %q", got, "3.0 KiB/s")
+	}
+}
+
+func TestParseBytes(t *testing.T) {
+	tests := map[string]struct {
+		s    string
+		want int64
+	}{
+		"plain number":  {s: "42", want: 42},
+		"bytes":         {s: "42B", want: 42},
+		"kilobytes":     {s: "64k", want: 64000},
+		"kibibytes":     {s: "64KiB", want: 65536},
+		"megabytes":     {s: "500MB", want: 500000000},
+		"spaced":        {s: " 500 MB ", want: 500000000},
+		"fraction":      {s: "1.5GiB", want: 1610612736},
+		"lower case":    {s: "2gib", want: 2147483648},
+		"terabytes":     {s: "1TB", want: 1e12},
+		"round to byte": {s: "0.5KiB", want: 512},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := units.ParseBytes(tc.s)
+			if err != nil {
+				t.Fatalf("ParseBytes(%q) failed: %v", tc.s, err)
+			}
+			if got != tc.want {
+				t.Errorf("ParseBytes(%q) = %d; want %d", tc.s, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseBytesError(t *testing.T) {
+	for _, s := range []string{"", "MB", "-5MB", "5XB", "5 megabytes", "1.2.3kB", "10EiB"} {
+		if n, err := units.ParseBytes(s); err == nil {
+			t.Errorf("ParseBytes(%q) = %d; want error", s, n)
+		}
+	}
+}
+
+func TestSizeFlag(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	var limit units.Size
+	fs.Var(&limit, "limit", "maximum size")
+	if err := fs.Parse([]string{"-limit", "500MB"}); err != nil {
+		t.Fatal(err)
+	}
+	if limit != 500000000 {
+		t.Errorf("-limit 500MB set %d; want 500000000", limit)
+	}
+	if got := limit.String(); got != "500.0 MB" {
+		t.Errorf("limit.String() = %q; want %q", got, "500.0 MB")
+	}
+}
@@ -1,28 +1,76 @@
+// Package anagram finds anagrams: words and phrases that use the same letters.
 package anagram
 
-import "strings"
+import (
+	"unicode"
 
+	"golang.org/x/text/cases"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Options configures IsAnagramWith. The zero value compares every character
+// and never treats a word as its own anagram.
+type Options struct {
+	IgnoreSpace    bool // skip white space, so "dirty room" matches "dormitory"
+	IgnorePunct    bool // skip punctuation and symbols
+	FoldDiacritics bool // compare letters without their accents, so "é" matches "e"
+	AllowSelf      bool // count a word as an anagram of itself
+}
+
+// IsAnagram reports whether s1 and s2 are anagrams, using the zero Options.
+// Case is compared with full Unicode case folding and text is normalized to
+// NFC first, so "ß" matches "ss" and precomposed accents match combining ones.
 func IsAnagram(s1, s2 string) bool {
-	wordOne := strings.ToLower(s1)
-	wordTwo := strings.ToLower(s2)
-	if len(wordOne) != len(wordTwo) || wordOne == wordTwo {
+	return IsAnagramWith(s1, s2, Options{})
+}
+
+// IsAnagramWith reports whether s1 and s2 are anagrams under opts.
+func IsAnagramWith(s1, s2 string, opts Options) bool {
+	a := opts.normalize(s1)
+	b := opts.normalize(s2)
+	if len(a) != len(b) {
+		return false
+	}
+	if !opts.AllowSelf && string(a) == string(b) {
 		return false
 	}
 
 	chars := make(map[rune]int)
-	for _, c := range wordOne {
+	for _, c := range a {
 		chars[c]++
 	}
-	for _, c := range wordTwo {
+	for _, c := range b {
 		chars[c]--
 	}
-
 	for _, count := range chars {
 		if count != 0 {
 			return false
 		}
 	}
-
 	return true
+}
+
+// normalize returns the runes of s that opts compares: case folded, in NFC,
+// without accents if requested, and without any characters opts ignores.
+func (opts Options) normalize(s string) []rune {
+	s = cases.Fold().String(s)
+	if opts.FoldDiacritics {
+		t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)))
+		s, _, _ = transform.String(t, s)
+	}
+	s = norm.NFC.String(s)
 
+	var out []rune
+	for _, r := range s {
+		if opts.IgnoreSpace && unicode.IsSpace(r) {
+			continue
+		}
+		if opts.IgnorePunct && (unicode.IsPunct(r) || unicode.IsSymbol(r)) {
+			continue
+		}
+		out = append(out, r)
+	}
+	return out
 }
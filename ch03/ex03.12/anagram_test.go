@@ -32,3 +32,58 @@ func TestAnagram(t *testing.T) {
 		})
 	}
 }
+
+func TestAnagramUnicode(t *testing.T) {
+	tests := map[string]struct {
+		s1       string
+		s2       string
+		expected bool
+	}{
+		"sharp s folds to ss, so these are one word": {s1: "ßa", s2: "ssa", expected: false},
+		"sharp s anagram": {s1: "aß", s2: "sas", expected: true},
+		"NFC and NFD agree": {s1: "\u00e9te", s2: "te\u0301e", expected: true},
+		"NFC and NFD are the same word": {s1: "\u00e9t\u00e9", s2: "e\u0301te\u0301", expected: false},
+		"final sigma": {s1: "σας", s2: "ΣΑΣ", expected: false},
+		"final sigma anagram": {s1: "ας", s2: "ΣΑ", expected: true},
+		"cyrillic": {s1: "кот", s2: "ток", expected: true},
+		"accents still matter": {s1: "résumé", s2: "resume", expected: false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual := anagram.IsAnagram(tc.s1, tc.s2)
+			if tc.expected != actual {
+				t.Errorf("IsAnagram(%q, %q): expected %t; actual %t", tc.s1, tc.s2, tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestAnagramWith(t *testing.T) {
+	tests := map[string]struct {
+		s1       string
+		s2       string
+		opts     anagram.Options
+		expected bool
+	}{
+		"spaces count by default": {s1: "dirty room", s2: "dormitory", opts: anagram.Options{}, expected: false},
+		"ignore spaces": {s1: "dirty room", s2: "dormitory", opts: anagram.Options{IgnoreSpace: true}, expected: true},
+		"punctuation counts by default": {s1: "Eleven plus two", s2: "Twelve plus one!", opts: anagram.Options{IgnoreSpace: true}, expected: false},
+		"ignore punctuation": {s1: "Eleven plus two", s2: "Twelve plus one!", opts: anagram.Options{IgnoreSpace: true, IgnorePunct: true}, expected: true},
+		"diacritics count by default": {s1: "résumé", s2: "sumere", opts: anagram.Options{}, expected: false},
+		"fold diacritics": {s1: "résumé", s2: "sumere", opts: anagram.Options{FoldDiacritics: true}, expected: true},
+		"fold combining diacritics": {s1: "re\u0301sume\u0301", s2: "sumere", opts: anagram.Options{FoldDiacritics: true}, expected: true},
+		"self is not an anagram": {s1: "Tab", s2: "tab", opts: anagram.Options{}, expected: false},
+		"allow self": {s1: "Tab", s2: "tab", opts: anagram.Options{AllowSelf: true}, expected: true},
+		"allow self across forms": {s1: "\u00e9", s2: "E\u0301", opts: anagram.Options{AllowSelf: true}, expected: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual := anagram.IsAnagramWith(tc.s1, tc.s2, tc.opts)
+			if tc.expected != actual {
+				t.Errorf("IsAnagramWith(%q, %q, %+v): expected %t; actual %t", tc.s1, tc.s2, tc.opts, tc.expected, actual)
+			}
+		})
+	}
+}
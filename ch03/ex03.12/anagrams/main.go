@@ -0,0 +1,62 @@
+// Anagrams looks up anagrams in a word list.
+//
+// Usage:
+//
+//	anagrams [-words file] word ...
+//	anagrams [-words file] -phrase [-limit n] [-max n] [-min n] phrase
+//
+// In the first form, anagrams prints every word in the list that is an
+// anagram of each argument. In the second form, it joins its arguments into
+// one phrase and prints combinations of words that use exactly its letters.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"anagram"
+)
+
+var (
+	words    = flag.String("words", "/usr/share/dict/words", "word list, one word per line")
+	phrase   = flag.Bool("phrase", false, "find multi-word anagrams of the arguments as one phrase")
+	limit    = flag.Int("limit", 100, "with -phrase, stop after this many results (0 means no limit)")
+	maxWords = flag.Int("max", 3, "with -phrase, use at most this many words (0 means no limit)")
+	minLen   = flag.Int("min", 2, "with -phrase, ignore words shorter than this")
+)
+
+func main() {
+	flag.Parse()
+	if flag.NArg() == 0 {
+		fmt.Fprintf(os.Stderr, "usage: anagrams [flags] word ...\n")
+		flag.PrintDefaults()
+		os.Exit(2)
+	}
+
+	f, err := os.Open(*words)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "anagrams: %v\n", err)
+		os.Exit(1)
+	}
+	ix, err := anagram.Load(f)
+	f.Close()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "anagrams: %s: %v\n", *words, err)
+		os.Exit(1)
+	}
+
+	if *phrase {
+		opts := anagram.Search{Limit: *limit, MaxWords: *maxWords, MinLen: *minLen}
+		for _, p := range ix.Phrases(strings.Join(flag.Args(), " "), opts) {
+			fmt.Println(strings.Join(p, " "))
+		}
+		os.Exit(0)
+	}
+
+	for _, w := range flag.Args() {
+		fmt.Printf("%s: %s\n", w, strings.Join(ix.Anagrams(w), " "))
+	}
+	os.Exit(0)
+}
@@ -1,3 +1,5 @@
 module anagram
 
 go 1.16
+
+require golang.org/x/text v0.14.0
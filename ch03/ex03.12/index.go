@@ -0,0 +1,228 @@
+package anagram
+
+import (
+	"bufio"
+	"io"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// An Index groups words by their signature so that all anagrams of a word
+// can be found with one map lookup.
+type Index struct {
+	groups map[string][]string // signature -> words, in the order added
+	seen   map[string]bool     // words already added
+}
+
+// NewIndex returns an empty Index.
+func NewIndex() *Index {
+	return &Index{
+		groups: make(map[string][]string),
+		seen:   make(map[string]bool),
+	}
+}
+
+// Load reads a word list from r, one word per line, and returns an Index of
+// its words. Blank lines and surrounding space are ignored.
+func Load(r io.Reader) (*Index, error) {
+	ix := NewIndex()
+	input := bufio.NewScanner(r)
+	for input.Scan() {
+		ix.Add(input.Text())
+	}
+	if err := input.Err(); err != nil {
+		return nil, err
+	}
+	return ix, nil
+}
+
+// Signature returns the canonical form of s shared by all of its anagrams:
+// its letters, case folded and normalized as IsAnagram does, then sorted.
+// Non-letters are dropped, so a phrase and its words have compatible
+// signatures.
+func Signature(s string) string {
+	var letters []rune
+	for _, r := range (Options{}).normalize(s) {
+		if unicode.IsLetter(r) {
+			letters = append(letters, r)
+		}
+	}
+	sort.Slice(letters, func(i, j int) bool { return letters[i] < letters[j] })
+	return string(letters)
+}
+
+// Add adds word to ix. Duplicates are ignored.
+func (ix *Index) Add(word string) {
+	word = strings.TrimSpace(word)
+	if word == "" || ix.seen[word] {
+		return
+	}
+	ix.seen[word] = true
+	sig := Signature(word)
+	if sig == "" {
+		return
+	}
+	ix.groups[sig] = append(ix.groups[sig], word)
+}
+
+// Len returns the number of distinct words in ix.
+func (ix *Index) Len() int {
+	return len(ix.seen)
+}
+
+// Anagrams returns the words in ix that are anagrams of word, excluding word
+// itself, in the order they were added.
+func (ix *Index) Anagrams(word string) []string {
+	var out []string
+	self := string(Options{}.normalize(word))
+	for _, w := range ix.groups[Signature(word)] {
+		if string(Options{}.normalize(w)) != self {
+			out = append(out, w)
+		}
+	}
+	return out
+}
+
+// Search limits a multi-word anagram search.
+type Search struct {
+	Limit    int // stop after this many phrases; 0 means no limit
+	MaxWords int // use at most this many words per phrase; 0 means no limit
+	MinLen   int // ignore words with fewer letters than this
+}
+
+// Phrases returns phrases made of words from ix that together use exactly
+// the letters of phrase. Each result lists its words longest first, and no
+// result is a reordering of another.
+func (ix *Index) Phrases(phrase string, opts Search) [][]string {
+	target := Signature(phrase)
+	if target == "" {
+		return nil
+	}
+
+	// Map each distinct letter of the phrase to a slot in a count vector.
+	slot := make(map[rune]int)
+	var need []int
+	for _, r := range target {
+		i, ok := slot[r]
+		if !ok {
+			i = len(need)
+			slot[r] = i
+			need = append(need, 0)
+		}
+		need[i]++
+	}
+
+	// Keep only the signatures whose letters fit inside the phrase.
+	type candidate struct {
+		sig    string
+		counts []int
+		size   int
+	}
+	var cands []candidate
+	for sig := range ix.groups {
+		counts := make([]int, len(need))
+		fits := true
+		size := 0
+		for _, r := range sig {
+			i, ok := slot[r]
+			if !ok {
+				fits = false
+				break
+			}
+			counts[i]++
+			size++
+			if counts[i] > need[i] {
+				fits = false
+				break
+			}
+		}
+		if fits && size >= opts.MinLen {
+			cands = append(cands, candidate{sig, counts, size})
+		}
+	}
+	// Longer words first, so the search uses up letters quickly; ties are
+	// broken by signature to make the results deterministic.
+	sort.Slice(cands, func(i, j int) bool {
+		if cands[i].size != cands[j].size {
+			return cands[i].size > cands[j].size
+		}
+		return cands[i].sig < cands[j].sig
+	})
+
+	var results [][]string
+	var sigs []string
+	left := len([]rune(target))
+	full := func() bool { return opts.Limit > 0 && len(results) >= opts.Limit }
+
+	// search tries candidates from start onward, so each combination of
+	// signatures is visited once, in non-increasing length order.
+	var search func(start int)
+	search = func(start int) {
+		if left == 0 {
+			results = ix.expand(results, sigs, opts.Limit)
+			return
+		}
+		if opts.MaxWords > 0 && len(sigs) == opts.MaxWords {
+			return
+		}
+		for c := start; c < len(cands) && !full(); c++ {
+			cand := cands[c]
+			if cand.size > left {
+				continue
+			}
+			ok := true
+			for i, n := range cand.counts {
+				if n > need[i] {
+					ok = false
+					break
+				}
+			}
+			if !ok {
+				continue
+			}
+			for i, n := range cand.counts {
+				need[i] -= n
+			}
+			left -= cand.size
+			sigs = append(sigs, cand.sig)
+			search(c)
+			sigs = sigs[:len(sigs)-1]
+			left += cand.size
+			for i, n := range cand.counts {
+				need[i] += n
+			}
+		}
+	}
+	search(0)
+	return results
+}
+
+// expand appends to results every phrase that can be made by choosing one
+// word for each signature in sigs, stopping once there are limit results.
+// When a signature repeats, its words are chosen in non-decreasing order so
+// that "a b" and "b a" are not both produced.
+func (ix *Index) expand(results [][]string, sigs []string, limit int) [][]string {
+	words := make([]string, len(sigs))
+	var choose func(k, from int)
+	choose = func(k, from int) {
+		if limit > 0 && len(results) >= limit {
+			return
+		}
+		if k == len(sigs) {
+			results = append(results, append([]string(nil), words...))
+			return
+		}
+		group := ix.groups[sigs[k]]
+		start := 0
+		if k > 0 && sigs[k] == sigs[k-1] {
+			start = from
+		}
+		for i := start; i < len(group); i++ {
+			words[k] = group[i]
+			choose(k+1, i)
+		}
+	}
+	choose(0, 0)
+	return results
+}
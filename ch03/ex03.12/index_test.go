@@ -0,0 +1,124 @@
+package anagram_test
+
+import (
+	"anagram"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const wordList = `
+listen
+silent
+enlist
+tinsel
+inlets
+google
+dormitory
+dirty
+room
+a
+an
+act
+cat
+tac
+Tab
+bat
+`
+
+func loadIndex(t *testing.T) *anagram.Index {
+	t.Helper()
+	ix, err := anagram.Load(strings.NewReader(wordList))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	return ix
+}
+
+func TestSignature(t *testing.T) {
+	tests := map[string]struct {
+		s        string
+		expected string
+	}{
+		"empty string":   {s: "", expected: ""},
+		"sorted letters": {s: "cab", expected: "abc"},
+		"case ignored":   {s: "CaB", expected: "abc"},
+		"spaces dropped": {s: "dirty room!", expected: "dimoorrty"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual := anagram.Signature(tc.s)
+			if tc.expected != actual {
+				t.Errorf("expected %q; actual %q", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestIndexAnagrams(t *testing.T) {
+	ix := loadIndex(t)
+	tests := map[string]struct {
+		word     string
+		expected []string
+	}{
+		"several anagrams":       {word: "listen", expected: []string{"silent", "enlist", "tinsel", "inlets"}},
+		"word not in list":       {word: "Netsil", expected: []string{"listen", "silent", "enlist", "tinsel", "inlets"}},
+		"no anagrams":            {word: "google", expected: nil},
+		"case-only match is not": {word: "tab", expected: []string{"bat"}},
+		"unknown letters":        {word: "xyz", expected: nil},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual := ix.Anagrams(tc.word)
+			if !reflect.DeepEqual(tc.expected, actual) {
+				t.Errorf("expected %q; actual %q", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestIndexPhrases(t *testing.T) {
+	ix := loadIndex(t)
+	tests := map[string]struct {
+		phrase   string
+		opts     anagram.Search
+		expected [][]string
+	}{
+		"two words": {
+			phrase:   "dormitory",
+			opts:     anagram.Search{MinLen: 4},
+			expected: [][]string{{"dormitory"}, {"dirty", "room"}},
+		},
+		"limited": {
+			phrase:   "dormitory",
+			opts:     anagram.Search{Limit: 1},
+			expected: [][]string{{"dormitory"}},
+		},
+		"max words": {
+			phrase:   "dirty room",
+			opts:     anagram.Search{MaxWords: 1},
+			expected: [][]string{{"dormitory"}},
+		},
+		"repeated signature": {
+			phrase:   "act cat",
+			opts:     anagram.Search{},
+			expected: [][]string{{"act", "act"}, {"act", "cat"}, {"act", "tac"}, {"cat", "cat"}, {"cat", "tac"}, {"tac", "tac"}},
+		},
+		"no solution": {
+			phrase:   "zzz",
+			opts:     anagram.Search{},
+			expected: nil,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual := ix.Phrases(tc.phrase, tc.opts)
+			if !reflect.DeepEqual(tc.expected, actual) {
+				t.Errorf("expected %q; actual %q", tc.expected, actual)
+			}
+		})
+	}
+}
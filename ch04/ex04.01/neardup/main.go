@@ -0,0 +1,143 @@
+// Neardup finds files and web pages with nearly the same text.
+//
+// Usage:
+//
+//	neardup [-k n] [-t bits] [-n hashes] file|url ...
+//
+// Neardup computes a SimHash fingerprint of each input's word shingles and
+// prints the groups of inputs whose fingerprints are within -t bits of one
+// another. Arguments that begin with http:// or https:// are fetched, and
+// markup is removed from HTML pages before fingerprinting. Each line of a
+// group shows an input's fingerprint, its distance in bits from the first
+// member of the group, and the Jaccard similarity of their shingles as
+// estimated by MinHash. Groups are separated by blank lines.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"ch04"
+)
+
+var (
+	shingle   = flag.Int("k", 2, "number of words per shingle")
+	threshold = flag.Int("t", 3, "greatest distance in bits between near-duplicates")
+	hashes    = flag.Int("n", 128, "number of MinHash functions for the similarity estimate")
+)
+
+type doc struct {
+	name string
+	fp   ch04.Fingerprint
+	mh   ch04.MinHash
+}
+
+func main() {
+	flag.Parse()
+	switch {
+	case *shingle < 1:
+		usage("-k must be at least 1")
+	case *hashes < 1:
+		usage("-n must be at least 1")
+	case *threshold < 0:
+		usage("-t must not be negative")
+	case flag.NArg() == 0:
+		usage("")
+	}
+
+	var docs []doc
+	var fps []ch04.Fingerprint
+	status := 0
+	for _, arg := range flag.Args() {
+		text, err := read(arg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "neardup: %v\n", err)
+			status = 1
+			continue
+		}
+		shingles := ch04.Shingles(text, *shingle)
+		d := doc{arg, ch04.SimHash(shingles), ch04.NewMinHash(shingles, *hashes)}
+		docs = append(docs, d)
+		fps = append(fps, d.fp)
+	}
+
+	for i, cluster := range ch04.Cluster(fps, *threshold) {
+		if i > 0 {
+			fmt.Println()
+		}
+		first := docs[cluster[0]]
+		for _, j := range cluster {
+			d := docs[j]
+			fmt.Printf("%016x  %2d  %.2f  %s\n", uint64(d.fp), d.fp.Distance(first.fp), d.mh.Similarity(first.mh), d.name)
+		}
+	}
+	os.Exit(status)
+}
+
+// usage reports msg, if any, and how to run neardup, and exits.
+func usage(msg string) {
+	if msg != "" {
+		fmt.Fprintf(os.Stderr, "neardup: %s\n", msg)
+	}
+	fmt.Fprintf(os.Stderr, "usage: neardup [flags] file|url ...\n")
+	flag.PrintDefaults()
+	os.Exit(2)
+}
+
+// read returns the text of the file or page named by arg.
+func read(arg string) (string, error) {
+	if !strings.HasPrefix(arg, "http://") && !strings.HasPrefix(arg, "https://") {
+		b, err := os.ReadFile(arg)
+		return string(b), err
+	}
+
+	resp, err := http.Get(arg)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("getting %s: %s", arg, resp.Status)
+	}
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("reading %s: %v", arg, err)
+	}
+	if strings.Contains(resp.Header.Get("Content-Type"), "html") {
+		return stripTags(string(b)), nil
+	}
+	return string(b), nil
+}
+
+// stripTags replaces HTML tags, and the contents of script and style
+// elements, with spaces. It is rough, but it keeps markup out of the
+// shingles.
+func stripTags(s string) string {
+	var b strings.Builder
+	lower := strings.ToLower(s)
+	for i := 0; i < len(s); {
+		if s[i] != '<' {
+			b.WriteByte(s[i])
+			i++
+			continue
+		}
+		skip := ">"
+		if strings.HasPrefix(lower[i:], "<script") {
+			skip = "</script>"
+		} else if strings.HasPrefix(lower[i:], "<style") {
+			skip = "</style>"
+		}
+		end := strings.Index(lower[i:], skip)
+		if end < 0 {
+			break
+		}
+		i += end + len(skip)
+		b.WriteByte(' ')
+	}
+
+	return b.String()
+}
@@ -1,29 +1,77 @@
 package ch04
 
-func PopCountDifference(s1, s2 [32]uint8) int {
-	popCountOne := sha256PopCount(s1)
-	popCountTwo := sha256PopCount(s2)
+import (
+	"encoding/binary"
+	"math/bits"
+)
 
-	if popCountOne > popCountTwo {
-		return popCountOne - popCountTwo
+// HammingDistance returns the number of bits that differ between a and b. It
+// compares eight bytes at a time. HammingDistance panics if a and b have
+// different lengths.
+func HammingDistance(a, b []byte) int {
+	if len(a) != len(b) {
+		panic("ch04: HammingDistance of slices with different lengths")
 	}
-	return popCountTwo - popCountOne
-}
-
-func sha256PopCount(shaSum [32]uint8) int {
 	var n int
-	for _, sum := range shaSum {
-		n += popCount(sum)
+	for len(a) >= 8 {
+		n += bits.OnesCount64(binary.LittleEndian.Uint64(a) ^ binary.LittleEndian.Uint64(b))
+		a, b = a[8:], b[8:]
+	}
+	for i := range a {
+		n += bits.OnesCount8(a[i] ^ b[i])
 	}
 
 	return n
 }
 
-func popCount(x uint8) int {
+// Distance256 returns the number of bits that differ between two SHA-256
+// digests.
+func Distance256(s1, s2 [32]byte) int {
+	return HammingDistance(s1[:], s2[:])
+}
+
+// Distance384 returns the number of bits that differ between two SHA-384
+// digests.
+func Distance384(s1, s2 [48]byte) int {
+	return HammingDistance(s1[:], s2[:])
+}
+
+// Distance512 returns the number of bits that differ between two SHA-512
+// digests.
+func Distance512(s1, s2 [64]byte) int {
+	return HammingDistance(s1[:], s2[:])
+}
+
+// PopCount returns the number of set bits in b.
+func PopCount(b []byte) int {
 	var n int
-	for ; x > 0; x = x&(x-1) {
-		n++
+	for len(b) >= 8 {
+		n += bits.OnesCount64(binary.LittleEndian.Uint64(b))
+		b = b[8:]
+	}
+	for _, x := range b {
+		n += bits.OnesCount8(x)
 	}
 
 	return n
 }
+
+// PopCountDifference returns the difference between the number of set bits
+// in s1 and in s2. Two different digests with the same number of set bits
+// give 0.
+//
+// Deprecated: exercise 4.1 asks for the number of differing bits, which is
+// what Distance256 returns.
+func PopCountDifference(s1, s2 [32]uint8) int {
+	popCountOne := sha256PopCount(s1)
+	popCountTwo := sha256PopCount(s2)
+
+	if popCountOne > popCountTwo {
+		return popCountOne - popCountTwo
+	}
+	return popCountTwo - popCountOne
+}
+
+func sha256PopCount(shaSum [32]uint8) int {
+	return PopCount(shaSum[:])
+}
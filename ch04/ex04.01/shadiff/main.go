@@ -0,0 +1,83 @@
+// Shadiff hashes two inputs and prints their digests and the number of bits
+// in which the digests differ.
+//
+// Usage:
+//
+//	shadiff [-sha 256|384|512] [-f] a b
+//
+// By default the arguments themselves are hashed. With -f, they name files
+// to hash instead, and "-" stands for the standard input.
+package main
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"flag"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+
+	"ch04"
+)
+
+var (
+	sha   = flag.Int("sha", 256, "digest size: 256, 384 or 512")
+	files = flag.Bool("f", false, "treat the arguments as files to hash")
+)
+
+func main() {
+	flag.Parse()
+	if flag.NArg() != 2 {
+		fmt.Fprintf(os.Stderr, "usage: shadiff [flags] a b\n")
+		flag.PrintDefaults()
+		os.Exit(2)
+	}
+
+	var newHash func() hash.Hash
+	switch *sha {
+	case 256:
+		newHash = sha256.New
+	case 384:
+		newHash = sha512.New384
+	case 512:
+		newHash = sha512.New
+	default:
+		fmt.Fprintf(os.Stderr, "shadiff: -sha must be 256, 384 or 512, not %d\n", *sha)
+		os.Exit(2)
+	}
+
+	var sums [2][]byte
+	for i, arg := range flag.Args() {
+		sum, err := digest(newHash(), arg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "shadiff: %v\n", err)
+			os.Exit(1)
+		}
+		sums[i] = sum
+		fmt.Printf("%x  %s\n", sum, arg)
+	}
+	fmt.Printf("%d of %d bits differ\n", ch04.HammingDistance(sums[0], sums[1]), len(sums[0])*8)
+}
+
+// digest returns the sum of arg, or of the file it names if -f is set.
+func digest(h hash.Hash, arg string) ([]byte, error) {
+	if !*files {
+		io.WriteString(h, arg)
+		return h.Sum(nil), nil
+	}
+
+	f := os.Stdin
+	if arg != "-" {
+		var err error
+		f, err = os.Open(arg)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+	}
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, fmt.Errorf("%s: %v", arg, err)
+	}
+	return h.Sum(nil), nil
+}
@@ -2,6 +2,7 @@ package ch04_test
 
 import (
 	"crypto/sha256"
+	"crypto/sha512"
 	"math/bits"
 	"testing"
 
@@ -59,3 +60,84 @@ func TestSha256PopCountDifference(t *testing.T) {
 		})
 	}
 }
+
+// hammingDistance counts differing bits one at a time.
+func hammingDistance(a, b []byte) int {
+	var n int
+	for i := range a {
+		for x := a[i] ^ b[i]; x != 0; x >>= 1 {
+			n += int(x & 1)
+		}
+	}
+
+	return n
+}
+
+func TestHammingDistance(t *testing.T) {
+	tests := map[string]struct {
+		a, b     []byte
+		expected int
+	}{
+		"empty slices": {
+			a:        []byte{},
+			b:        []byte{},
+			expected: 0,
+		},
+		"same popcount, different bits": {
+			a:        []byte{0x0f},
+			b:        []byte{0xf0},
+			expected: 8,
+		},
+		"one bit in the tail": {
+			a:        []byte{0, 0, 0, 0, 0, 0, 0, 0, 1},
+			b:        []byte{0, 0, 0, 0, 0, 0, 0, 0, 0},
+			expected: 1,
+		},
+		"every bit of a word": {
+			a:        []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+			b:        []byte{0, 0, 0, 0, 0, 0, 0, 0},
+			expected: 64,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual := ch04.HammingDistance(tc.a, tc.b)
+			if tc.expected != actual {
+				t.Errorf("ch04.HammingDistance: expected %d but got %d\n", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestDigestDistances(t *testing.T) {
+	x, X := []byte("x"), []byte("X")
+
+	s1, s2 := sha256.Sum256(x), sha256.Sum256(X)
+	if expected, actual := hammingDistance(s1[:], s2[:]), ch04.Distance256(s1, s2); expected != actual {
+		t.Errorf("ch04.Distance256: expected %d but got %d\n", expected, actual)
+	}
+
+	t1, t2 := sha512.Sum384(x), sha512.Sum384(X)
+	if expected, actual := hammingDistance(t1[:], t2[:]), ch04.Distance384(t1, t2); expected != actual {
+		t.Errorf("ch04.Distance384: expected %d but got %d\n", expected, actual)
+	}
+
+	u1, u2 := sha512.Sum512(x), sha512.Sum512(X)
+	if expected, actual := hammingDistance(u1[:], u2[:]), ch04.Distance512(u1, u2); expected != actual {
+		t.Errorf("ch04.Distance512: expected %d but got %d\n", expected, actual)
+	}
+
+	if actual := ch04.Distance256(s1, s1); actual != 0 {
+		t.Errorf("ch04.Distance256 of identical digests: expected 0 but got %d\n", actual)
+	}
+}
+
+func TestHammingDistancePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("ch04.HammingDistance: expected a panic for slices of different lengths\n")
+		}
+	}()
+	ch04.HammingDistance([]byte{1}, []byte{1, 2})
+}
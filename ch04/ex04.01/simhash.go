@@ -0,0 +1,205 @@
+package ch04
+
+import (
+	"hash/fnv"
+	"math/bits"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Shingles splits text into lower-case words and returns every run of k
+// consecutive words, joined by spaces. Texts with fewer than k words give a
+// single shingle of all their words, and empty texts give none. Shingles of
+// two or three words make fingerprints sensitive to word order as well as to
+// vocabulary.
+func Shingles(text string, k int) []string {
+	if k < 1 {
+		k = 1
+	}
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	if len(words) == 0 {
+		return nil
+	}
+	if len(words) < k {
+		return []string{strings.Join(words, " ")}
+	}
+	shingles := make([]string, 0, len(words)-k+1)
+	for i := 0; i+k <= len(words); i++ {
+		shingles = append(shingles, strings.Join(words[i:i+k], " "))
+	}
+
+	return shingles
+}
+
+// hash64 returns the 64-bit FNV-1a hash of s.
+func hash64(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return h.Sum64()
+}
+
+// mix scrambles x with the SplitMix64 finalizer, so that hashes differing in
+// a few bits come out unrelated.
+func mix(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+// A Fingerprint is a 64-bit SimHash of a document. Similar documents have
+// fingerprints that differ in few bits.
+type Fingerprint uint64
+
+// SimHash returns the SimHash of features. Each feature votes on every bit of
+// the fingerprint with its hash, once per occurrence, and each bit is set
+// where the votes for it outnumber the votes against it.
+func SimHash(features []string) Fingerprint {
+	var votes [64]int
+	for _, f := range features {
+		h := mix(hash64(f))
+		for i := range votes {
+			if h&(1<<uint(i)) != 0 {
+				votes[i]++
+			} else {
+				votes[i]--
+			}
+		}
+	}
+	var fp Fingerprint
+	for i, v := range votes {
+		if v > 0 {
+			fp |= 1 << uint(i)
+		}
+	}
+
+	return fp
+}
+
+// Distance returns the number of bits that differ between f and g.
+func (f Fingerprint) Distance(g Fingerprint) int {
+	return bits.OnesCount64(uint64(f ^ g))
+}
+
+// A MinHash is a signature of a set of features from which the Jaccard
+// similarity of two sets can be estimated. Element i is the least value of
+// the i-th hash function over the set.
+type MinHash []uint64
+
+// NewMinHash returns a MinHash of features with n hash functions. More hash
+// functions give a better estimate: the standard error is about 1/√n.
+func NewMinHash(features []string, n int) MinHash {
+	m := make(MinHash, n)
+	for i := range m {
+		m[i] = ^uint64(0)
+	}
+	for _, f := range features {
+		h := hash64(f)
+		for i := range m {
+			if v := mix(h + uint64(i)*0x9e3779b97f4a7c15); v < m[i] {
+				m[i] = v
+			}
+		}
+	}
+
+	return m
+}
+
+// Similarity returns the estimated Jaccard similarity of the sets behind m
+// and o: the fraction of hash functions on which they agree. It returns 0 if
+// m and o were made with different numbers of hash functions or if either
+// set was empty.
+func (m MinHash) Similarity(o MinHash) float64 {
+	if len(m) != len(o) || len(m) == 0 {
+		return 0
+	}
+	var same int
+	for i := range m {
+		if m[i] == o[i] && m[i] != ^uint64(0) {
+			same++
+		}
+	}
+
+	return float64(same) / float64(len(m))
+}
+
+// Cluster groups fps so that any two fingerprints within threshold bits of
+// each other end up in the same cluster, along with anything linked to them
+// through a chain of such pairs. It returns the clusters with at least two
+// members as lists of indexes into fps, each sorted, ordered by their first
+// index.
+//
+// To avoid comparing every pair, Cluster splits the 64 bits into threshold+1
+// bands: two fingerprints within threshold bits must agree on at least one
+// whole band, so only fingerprints that share a band are compared. With a
+// threshold of 64 or more, every pair is within it, and all of fps form one
+// cluster.
+func Cluster(fps []Fingerprint, threshold int) [][]int {
+	if threshold < 0 {
+		return nil
+	}
+	if threshold >= 64 {
+		if len(fps) < 2 {
+			return nil
+		}
+		all := make([]int, len(fps))
+		for i := range all {
+			all[i] = i
+		}
+		return [][]int{all}
+	}
+
+	parent := make([]int, len(fps))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	type key struct {
+		band int
+		bits uint64
+	}
+	nbands := threshold + 1
+	for b := 0; b < nbands; b++ {
+		lo, hi := b*64/nbands, (b+1)*64/nbands
+		mask := (^uint64(0) >> uint(64-(hi-lo))) << uint(lo)
+		buckets := make(map[key][]int)
+		for i, fp := range fps {
+			k := key{b, uint64(fp) & mask}
+			for _, j := range buckets[k] {
+				if fp.Distance(fps[j]) <= threshold {
+					if ri, rj := find(i), find(j); ri != rj {
+						parent[ri] = rj
+					}
+				}
+			}
+			buckets[k] = append(buckets[k], i)
+		}
+	}
+
+	groups := make(map[int][]int)
+	for i := range fps {
+		r := find(i)
+		groups[r] = append(groups[r], i)
+	}
+	var clusters [][]int
+	for _, g := range groups {
+		if len(g) > 1 {
+			clusters = append(clusters, g)
+		}
+	}
+	sort.Slice(clusters, func(i, j int) bool { return clusters[i][0] < clusters[j][0] })
+
+	return clusters
+}
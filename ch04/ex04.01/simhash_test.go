@@ -0,0 +1,164 @@
+package ch04_test
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"ch04"
+)
+
+const (
+	original = `It was the best of times, it was the worst of times, it was the age
+of wisdom, it was the age of foolishness, it was the epoch of belief, it
+was the epoch of incredulity, it was the season of Light, it was the
+season of Darkness, it was the spring of hope, it was the winter of
+despair, we had everything before us, we had nothing before us.`
+	edited = `It was the best of times, it was the worst of times, it was the age
+of wisdom, it was the age of foolishness, it was the epoch of belief, it
+was the epoch of incredulity, it was the season of Light, it was the
+season of Darkness, it was the spring of hope, it was the winter of
+despair, we had everything before us, we had nothing at all before us.`
+	unrelated = `Call me Ishmael. Some years ago, never mind how long precisely,
+having little or no money in my purse, and nothing particular to interest
+me on shore, I thought I would sail about a little and see the watery part
+of the world.`
+)
+
+func TestShingles(t *testing.T) {
+	tests := map[string]struct {
+		text     string
+		k        int
+		expected []string
+	}{
+		"empty text": {
+			text:     " ,. ",
+			k:        2,
+			expected: nil,
+		},
+		"fewer words than k": {
+			text:     "Hello, World",
+			k:        3,
+			expected: []string{"hello world"},
+		},
+		"word pairs": {
+			text:     "The cat sat",
+			k:        2,
+			expected: []string{"the cat", "cat sat"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual := ch04.Shingles(tc.text, tc.k)
+			if !reflect.DeepEqual(tc.expected, actual) {
+				t.Errorf("ch04.Shingles(%q, %d): expected %q but got %q\n", tc.text, tc.k, tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestSimHash(t *testing.T) {
+	a := ch04.SimHash(ch04.Shingles(original, 2))
+	b := ch04.SimHash(ch04.Shingles(edited, 2))
+	c := ch04.SimHash(ch04.Shingles(unrelated, 2))
+
+	if d := a.Distance(ch04.SimHash(ch04.Shingles(strings.ToUpper(original), 2))); d != 0 {
+		t.Errorf("ch04.SimHash: expected distance 0 for the same words but got %d\n", d)
+	}
+	near, far := a.Distance(b), a.Distance(c)
+	if near >= far {
+		t.Errorf("ch04.SimHash: near-duplicate distance %d is not less than unrelated distance %d\n", near, far)
+	}
+	if near > 10 {
+		t.Errorf("ch04.SimHash: expected a near-duplicate distance of at most 10 but got %d\n", near)
+	}
+}
+
+func TestMinHash(t *testing.T) {
+	a := ch04.NewMinHash(ch04.Shingles(original, 2), 256)
+	b := ch04.NewMinHash(ch04.Shingles(edited, 2), 256)
+	c := ch04.NewMinHash(ch04.Shingles(unrelated, 2), 256)
+
+	if s := a.Similarity(a); s != 1 {
+		t.Errorf("ch04.MinHash.Similarity: expected 1 for the same set but got %g\n", s)
+	}
+	// The texts share 40 of their 44 distinct shingles, for a Jaccard
+	// similarity of about 0.91.
+	if s := a.Similarity(b); math.Abs(s-0.91) > 0.1 {
+		t.Errorf("ch04.MinHash.Similarity: expected about 0.91 for the edited text but got %g\n", s)
+	}
+	if s := a.Similarity(c); s > 0.1 {
+		t.Errorf("ch04.MinHash.Similarity: expected about 0 for unrelated texts but got %g\n", s)
+	}
+	if s := a.Similarity(ch04.NewMinHash(nil, 8)); s != 0 {
+		t.Errorf("ch04.MinHash.Similarity: expected 0 for different sizes but got %g\n", s)
+	}
+}
+
+func TestCluster(t *testing.T) {
+	fps := []ch04.Fingerprint{
+		0x0000,
+		0xff00ff00ff00ff00,
+		0x0003,
+		0xff00ff00ff00ff01,
+		0x000f,
+		0x1234567812345678,
+	}
+	tests := map[string]struct {
+		threshold int
+		expected  [][]int
+	}{
+		"exact matches only": {
+			threshold: 0,
+			expected:  nil,
+		},
+		"one bit": {
+			threshold: 1,
+			expected:  [][]int{{1, 3}},
+		},
+		"chained through a middle fingerprint": {
+			threshold: 2,
+			expected:  [][]int{{0, 2, 4}, {1, 3}},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual := ch04.Cluster(fps, tc.threshold)
+			if !reflect.DeepEqual(tc.expected, actual) {
+				t.Errorf("ch04.Cluster(fps, %d): expected %v but got %v\n", tc.threshold, tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestClusterWholeWidth(t *testing.T) {
+	fps := []ch04.Fingerprint{0, 0xffffffffffffffff, 0xf0f0f0f0f0f0f0f0}
+	tests := map[string]struct {
+		threshold int
+		expected  [][]int
+	}{
+		"63 bits":      {threshold: 63, expected: [][]int{{0, 1, 2}}},
+		"64 bits":      {threshold: 64, expected: [][]int{{0, 1, 2}}},
+		"beyond width": {threshold: 100, expected: [][]int{{0, 1, 2}}},
+		"just short":   {threshold: 31, expected: nil},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual := ch04.Cluster(fps, tc.threshold)
+			if !reflect.DeepEqual(tc.expected, actual) {
+				t.Errorf("ch04.Cluster(fps, %d): expected %v but got %v\n", tc.threshold, tc.expected, actual)
+			}
+		})
+	}
+
+	if actual := ch04.Cluster(fps[:2], 63); actual != nil {
+		t.Errorf("ch04.Cluster of complements, 63: expected nil but got %v\n", actual)
+	}
+	if actual := ch04.Cluster(fps[:2], 64); !reflect.DeepEqual(actual, [][]int{{0, 1}}) {
+		t.Errorf("ch04.Cluster of complements, 64: expected [[0 1]] but got %v\n", actual)
+	}
+}
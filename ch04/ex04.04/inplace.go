@@ -0,0 +1,139 @@
+package rotate
+
+// A Func rotates xs in place by k steps. Every in-place rotation in this
+// package has this signature, so callers and benchmarks can swap one
+// algorithm for another. As with the other functions, k can be zero, larger
+// than the size of the slice, or negative.
+type Func[T any] func(xs []T, k int)
+
+// JuggleLeft rotates a slice of any type leftward by k steps in place, using
+// the juggling (cycle-leader) algorithm. The rotation splits the slice into
+// gcd(len(xs), k) cycles and moves each element exactly once, straight to
+// its final position.
+func JuggleLeft[T any](xs []T, k int) {
+	l := len(xs)
+	if l < 2 {
+		return
+	}
+	r := mod(k, l)
+	if r == 0 {
+		return
+	}
+
+	for start, cycles := 0, gcd(l, r); start < cycles; start++ {
+		tmp := xs[start]
+		i := start
+		for {
+			j := i + r
+			if j >= l {
+				j -= l
+			}
+			if j == start {
+				break
+			}
+			xs[i] = xs[j]
+			i = j
+		}
+		xs[i] = tmp
+	}
+}
+
+// JuggleRight rotates a slice of any type rightward by k steps in place,
+// using the juggling (cycle-leader) algorithm.
+func JuggleRight[T any](xs []T, k int) {
+	JuggleLeft(xs, -k)
+}
+
+// BlockSwapLeft rotates a slice of any type leftward by k steps in place,
+// using the Gries-Mills block-swap algorithm. To rotate AB into BA, it
+// repeatedly swaps the shorter block with the far end of the longer one,
+// which leaves a smaller rotation to finish.
+func BlockSwapLeft[T any](xs []T, k int) {
+	l := len(xs)
+	if l < 2 {
+		return
+	}
+	r := mod(k, l)
+	if r == 0 {
+		return
+	}
+
+	// i and j are the sizes of the blocks on either side of xs[r] that
+	// remain to be rotated.
+	i, j := r, l-r
+	for i != j {
+		if i < j {
+			swapBlocks(xs, r-i, r+j-i, i)
+			j -= i
+		} else {
+			swapBlocks(xs, r-i, r, j)
+			i -= j
+		}
+	}
+	swapBlocks(xs, r-i, r, i)
+}
+
+// BlockSwapRight rotates a slice of any type rightward by k steps in place,
+// using the Gries-Mills block-swap algorithm.
+func BlockSwapRight[T any](xs []T, k int) {
+	BlockSwapLeft(xs, -k)
+}
+
+// RotateLeft rotates a slice of any type leftward by k steps in place, using
+// whichever approach the benchmarks in this package found fastest. When the
+// slice is long but the rotation moves only a few elements one way or the
+// other, it sets them aside in a small buffer and shifts the rest with copy,
+// which compiles to a memmove. Otherwise it uses the reversal algorithm, which
+// beat juggling and block swapping at every size measured.
+func RotateLeft[T any](xs []T, k int) {
+	l := len(xs)
+	if l < 2 {
+		return
+	}
+	r := mod(k, l)
+	if r == 0 {
+		return
+	}
+	if l <= 2*shortShift {
+		ReverseLeft(xs, r)
+		return
+	}
+
+	var buf [shortShift]T
+	switch {
+	case r <= shortShift:
+		n := copy(buf[:], xs[:r])
+		copy(xs, xs[r:])
+		copy(xs[l-r:], buf[:n])
+	case l-r <= shortShift:
+		n := copy(buf[:], xs[r:])
+		copy(xs[l-r:], xs[:r])
+		copy(xs, buf[:n])
+	default:
+		ReverseLeft(xs, r)
+	}
+}
+
+// RotateRight rotates a slice of any type rightward by k steps in place,
+// choosing an algorithm as RotateLeft does.
+func RotateRight[T any](xs []T, k int) {
+	RotateLeft(xs, -k)
+}
+
+// shortShift is the largest rotation that RotateLeft handles with a buffer.
+const shortShift = 16
+
+// swapBlocks swaps the n elements starting at xs[i] with the n elements
+// starting at xs[j]. The blocks must not overlap.
+func swapBlocks[T any](xs []T, i, j, n int) {
+	for ; n > 0; i, j, n = i+1, j+1, n-1 {
+		xs[i], xs[j] = xs[j], xs[i]
+	}
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
@@ -0,0 +1,159 @@
+package rotate_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/telemachus/gopl/ch04/rotate"
+)
+
+var leftFuncs = map[string]rotate.Func[int]{
+	"ReverseLeft":   rotate.ReverseLeft[int],
+	"JuggleLeft":    rotate.JuggleLeft[int],
+	"BlockSwapLeft": rotate.BlockSwapLeft[int],
+	"RotateLeft":    rotate.RotateLeft[int],
+}
+
+var rightFuncs = map[string]rotate.Func[int]{
+	"ReverseRight":   rotate.ReverseRight[int],
+	"JuggleRight":    rotate.JuggleRight[int],
+	"BlockSwapRight": rotate.BlockSwapRight[int],
+	"RotateRight":    rotate.RotateRight[int],
+}
+
+func TestInPlaceLeft(t *testing.T) {
+	t.Parallel()
+
+	for name, f := range leftFuncs {
+		for msg, tc := range newTestCases() {
+			name, f, tc := name, f, tc
+
+			t.Run(name+"/"+msg, func(t *testing.T) {
+				t.Parallel()
+
+				got := make([]int, len(tc.orig))
+				copy(got, tc.orig)
+				f(got, tc.r)
+
+				if !cmp.Equal(got, tc.wantl) {
+					t.Errorf(
+						"rotate.%s(%+v, %d) = %+v; want %+v\n",
+						name,
+						tc.orig,
+						tc.r,
+						got,
+						tc.wantl,
+					)
+				}
+			})
+		}
+	}
+}
+
+func TestInPlaceRight(t *testing.T) {
+	t.Parallel()
+
+	for name, f := range rightFuncs {
+		for msg, tc := range newTestCases() {
+			name, f, tc := name, f, tc
+
+			t.Run(name+"/"+msg, func(t *testing.T) {
+				t.Parallel()
+
+				got := make([]int, len(tc.orig))
+				copy(got, tc.orig)
+				f(got, tc.r)
+
+				if !cmp.Equal(got, tc.wantr) {
+					t.Errorf(
+						"rotate.%s(%+v, %d) = %+v; want %+v\n",
+						name,
+						tc.orig,
+						tc.r,
+						got,
+						tc.wantr,
+					)
+				}
+			})
+		}
+	}
+}
+
+// TestInPlaceAgree checks every algorithm against the others for all
+// rotations of slices up to a modest size.
+func TestInPlaceAgree(t *testing.T) {
+	t.Parallel()
+
+	for l := 0; l <= 24; l++ {
+		for k := -l - 1; k <= l+1; k++ {
+			want := make([]int, l)
+			for i := range want {
+				want[i] = i
+			}
+			want = rotate.Left(want, k)
+
+			for name, f := range leftFuncs {
+				got := make([]int, l)
+				for i := range got {
+					got[i] = i
+				}
+				f(got, k)
+
+				if !cmp.Equal(got, want) {
+					t.Errorf("rotate.%s(len %d, %d) = %+v; want %+v\n", name, l, k, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestCopyDoesNotClobber(t *testing.T) {
+	t.Parallel()
+
+	backing := []int{1, 2, 3, 4, 5, 99, 99}
+	xs := backing[:5]
+
+	gotr := rotate.Right(xs, 2)
+	gotl := rotate.Left(xs, 2)
+
+	if !cmp.Equal(gotr, []int{4, 5, 1, 2, 3}) {
+		t.Errorf("rotate.Right(%+v, 2) = %+v; want [4 5 1 2 3]\n", xs, gotr)
+	}
+	if !cmp.Equal(gotl, []int{3, 4, 5, 1, 2}) {
+		t.Errorf("rotate.Left(%+v, 2) = %+v; want [3 4 5 1 2]\n", xs, gotl)
+	}
+	if !cmp.Equal(backing, []int{1, 2, 3, 4, 5, 99, 99}) {
+		t.Errorf("backing array changed to %+v\n", backing)
+	}
+}
+
+func TestGenericLeft(t *testing.T) {
+	t.Parallel()
+
+	got := rotate.Left([]string{"a", "b", "c"}, 1)
+	if !cmp.Equal(got, []string{"b", "c", "a"}) {
+		t.Errorf("rotate.Left([a b c], 1) = %+v; want [b c a]\n", got)
+	}
+}
+
+// BenchmarkInPlace compares the in-place algorithms across slice sizes and
+// rotation distances. The results decide how RotateLeft works. A rotation by
+// a third of the slice gives the juggling algorithm few cycles; a rotation by
+// one is the common sliding-window case.
+func BenchmarkInPlace(b *testing.B) {
+	for _, size := range []int{8, 64, 512, 4096, 65536, 1 << 20} {
+		nums := make([]int, size)
+		for _, k := range []int{1, size / 3} {
+			for _, name := range []string{"ReverseLeft", "JuggleLeft", "BlockSwapLeft", "RotateLeft"} {
+				f := leftFuncs[name]
+
+				b.Run(fmt.Sprintf("%s/size=%d/k=%d", name, size, k), func(b *testing.B) {
+					for i := 0; i < b.N; i++ {
+						f(nums, k)
+					}
+				})
+			}
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package rotate
+
+// A Ring is a double-ended queue stored in a circular buffer. Pushing and
+// popping at either end take amortized constant time, and Rotate works in
+// place: on a full buffer it only moves the head index. A Ring models a
+// sliding window without rotating a slice on every step.
+//
+// A Ring is either unbounded, in which case its buffer grows as needed, or
+// bounded, in which case pushing onto a full Ring evicts the element at the
+// other end. The zero value is an empty, unbounded Ring ready to use.
+type Ring[T any] struct {
+	buf   []T
+	head  int // index in buf of the first element
+	n     int // number of elements
+	limit int // maximum number of elements, or 0 for no limit
+}
+
+// NewRing returns an empty Ring that holds at most limit elements. If limit
+// is zero or less, the Ring is unbounded.
+func NewRing[T any](limit int) *Ring[T] {
+	if limit <= 0 {
+		return &Ring[T]{}
+	}
+	return &Ring[T]{buf: make([]T, limit), limit: limit}
+}
+
+// Len returns the number of elements in r.
+func (r *Ring[T]) Len() int {
+	return r.n
+}
+
+// Limit returns the most elements r can hold, or 0 if r is unbounded.
+func (r *Ring[T]) Limit() int {
+	return r.limit
+}
+
+// index returns the position in r.buf of the element at logical index i.
+func (r *Ring[T]) index(i int) int {
+	i += r.head
+	if i >= len(r.buf) {
+		i -= len(r.buf)
+	}
+	return i
+}
+
+// At returns the element at index i, counting from the front. It panics if
+// i is out of range, as indexing a slice does.
+func (r *Ring[T]) At(i int) T {
+	if i < 0 || i >= r.n {
+		panic("rotate: Ring index out of range")
+	}
+	return r.buf[r.index(i)]
+}
+
+// full reports whether the buffer has no free slot.
+func (r *Ring[T]) full() bool {
+	return r.n == len(r.buf)
+}
+
+// atLimit reports whether r is bounded and holds as many elements as it may.
+func (r *Ring[T]) atLimit() bool {
+	return r.limit > 0 && r.n == r.limit
+}
+
+// grow makes room for at least one more element. It is called only when the
+// buffer is full and r is below its limit.
+func (r *Ring[T]) grow() {
+	size := 2 * len(r.buf)
+	if size == 0 {
+		size = 8
+	}
+	if r.limit > 0 && size > r.limit {
+		size = r.limit
+	}
+	r.resize(size)
+}
+
+// resize moves r's elements, front first, to a new buffer of the given size.
+func (r *Ring[T]) resize(size int) {
+	buf := make([]T, size)
+	if end := r.head + r.n; end <= len(r.buf) {
+		copy(buf, r.buf[r.head:end])
+	} else {
+		m := copy(buf, r.buf[r.head:])
+		copy(buf[m:], r.buf[:end-len(r.buf)])
+	}
+	r.buf = buf
+	r.head = 0
+}
+
+// PushBack adds x at the back of r. If r is bounded and full, PushBack first
+// removes the front element and returns it with evicted set to true.
+func (r *Ring[T]) PushBack(x T) (old T, evicted bool) {
+	switch {
+	case r.atLimit():
+		old, evicted = r.PopFront()
+	case r.full():
+		r.grow()
+	}
+	r.buf[r.index(r.n)] = x
+	r.n++
+	return old, evicted
+}
+
+// PushFront adds x at the front of r. If r is bounded and full, PushFront
+// first removes the back element and returns it with evicted set to true.
+func (r *Ring[T]) PushFront(x T) (old T, evicted bool) {
+	switch {
+	case r.atLimit():
+		old, evicted = r.PopBack()
+	case r.full():
+		r.grow()
+	}
+	r.head--
+	if r.head < 0 {
+		r.head += len(r.buf)
+	}
+	r.buf[r.head] = x
+	r.n++
+	return old, evicted
+}
+
+// PopFront removes and returns the front element of r. The boolean result is
+// false if r is empty.
+func (r *Ring[T]) PopFront() (T, bool) {
+	var zero T
+	if r.n == 0 {
+		return zero, false
+	}
+	x := r.buf[r.head]
+	r.buf[r.head] = zero // don't hold on to popped values
+	r.head = r.index(1)
+	r.n--
+	return x, true
+}
+
+// PopBack removes and returns the back element of r. The boolean result is
+// false if r is empty.
+func (r *Ring[T]) PopBack() (T, bool) {
+	var zero T
+	if r.n == 0 {
+		return zero, false
+	}
+	i := r.index(r.n - 1)
+	x := r.buf[i]
+	r.buf[i] = zero
+	r.n--
+	return x, true
+}
+
+// Rotate rotates the elements of r leftward by k steps, so that the element
+// at index k becomes the front. As with the slice functions, k can be zero,
+// larger than r.Len(), or negative. When the elements fill the buffer, Rotate
+// only moves the head index. Otherwise the free slots sit between the back
+// and the front, so Rotate moves the elements on the shorter side across
+// them one at a time, which takes at most r.Len()/2 moves. It never
+// allocates or changes the size of the buffer.
+func (r *Ring[T]) Rotate(k int) {
+	if r.n < 2 {
+		return
+	}
+	steps := mod(k, r.n)
+	switch {
+	case steps == 0:
+	case r.full():
+		r.head = r.index(steps)
+	case steps <= r.n/2:
+		for ; steps > 0; steps-- {
+			x, _ := r.PopFront()
+			r.PushBack(x)
+		}
+	default:
+		for steps = r.n - steps; steps > 0; steps-- {
+			x, _ := r.PopBack()
+			r.PushFront(x)
+		}
+	}
+}
+
+// Do calls f on each element of r, from front to back.
+func (r *Ring[T]) Do(f func(T)) {
+	for i := 0; i < r.n; i++ {
+		f(r.buf[r.index(i)])
+	}
+}
+
+// Linearize rearranges r's buffer in place so that its elements are
+// contiguous, front first, and returns them as a slice. The slice shares
+// r's storage: it is valid only until r is next changed.
+func (r *Ring[T]) Linearize() []T {
+	if r.head != 0 {
+		ReverseLeft(r.buf, r.head)
+		r.head = 0
+	}
+	return r.buf[:r.n]
+}
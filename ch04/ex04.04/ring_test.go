@@ -0,0 +1,207 @@
+package rotate_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/telemachus/gopl/ch04/rotate"
+)
+
+// contents collects the elements of r with Do.
+func contents(r *rotate.Ring[int]) []int {
+	xs := []int{}
+	r.Do(func(x int) { xs = append(xs, x) })
+	return xs
+}
+
+// newRings returns rings holding xs in several layouts: a full bounded ring,
+// a bounded ring with a free slot, and an unbounded ring whose elements wrap
+// around the end of its buffer.
+func newRings(xs []int) map[string]*rotate.Ring[int] {
+	full := rotate.NewRing[int](len(xs))
+	spare := rotate.NewRing[int](len(xs) + 1)
+	wrapped := rotate.NewRing[int](0)
+	for i := len(xs) - 1; i >= 0; i-- {
+		full.PushFront(xs[i])
+		wrapped.PushFront(xs[i])
+	}
+	for _, x := range xs {
+		spare.PushBack(x)
+	}
+	return map[string]*rotate.Ring[int]{
+		"full":    full,
+		"spare":   spare,
+		"wrapped": wrapped,
+	}
+}
+
+func TestRingRotate(t *testing.T) {
+	t.Parallel()
+
+	for msg, tc := range newTestCases() {
+		for layout, r := range newRings(tc.orig) {
+			msg, layout, r, tc := msg, layout, r, tc
+
+			t.Run(msg+"/"+layout, func(t *testing.T) {
+				t.Parallel()
+
+				r.Rotate(tc.r)
+				got := contents(r)
+
+				if !cmp.Equal(got, tc.wantl) {
+					t.Errorf(
+						"Ring(%+v).Rotate(%d) = %+v; want %+v\n",
+						tc.orig,
+						tc.r,
+						got,
+						tc.wantl,
+					)
+				}
+				for i, want := range tc.wantl {
+					if x := r.At(i); x != want {
+						t.Errorf("At(%d) = %d; want %d\n", i, x, want)
+					}
+				}
+				if lin := r.Linearize(); !cmp.Equal(lin, tc.wantl, cmpopts.EquateEmpty()) {
+					t.Errorf("Linearize() = %+v; want %+v\n", lin, tc.wantl)
+				}
+			})
+		}
+	}
+}
+
+func TestRingDeque(t *testing.T) {
+	t.Parallel()
+
+	r := rotate.NewRing[int](0)
+	for i := 1; i <= 20; i++ {
+		r.PushBack(i)
+		r.PushFront(-i)
+	}
+	if r.Len() != 40 {
+		t.Fatalf("Len() = %d; want 40\n", r.Len())
+	}
+	for i := 20; i >= 1; i-- {
+		if x, ok := r.PopFront(); x != -i || !ok {
+			t.Errorf("PopFront() = %d, %t; want %d, true\n", x, ok, -i)
+		}
+		if x, ok := r.PopBack(); x != i || !ok {
+			t.Errorf("PopBack() = %d, %t; want %d, true\n", x, ok, i)
+		}
+	}
+	if x, ok := r.PopFront(); ok {
+		t.Errorf("PopFront() on empty ring = %d, true; want 0, false\n", x)
+	}
+	if x, ok := r.PopBack(); ok {
+		t.Errorf("PopBack() on empty ring = %d, true; want 0, false\n", x)
+	}
+}
+
+func TestRingBounded(t *testing.T) {
+	t.Parallel()
+
+	r := rotate.NewRing[int](3)
+	var evicted []int
+	for i := 1; i <= 5; i++ {
+		if old, ok := r.PushBack(i); ok {
+			evicted = append(evicted, old)
+		}
+	}
+	if want := []int{1, 2}; !cmp.Equal(evicted, want) {
+		t.Errorf("PushBack evicted %+v; want %+v\n", evicted, want)
+	}
+	if got, want := contents(r), []int{3, 4, 5}; !cmp.Equal(got, want) {
+		t.Errorf("after PushBack: %+v; want %+v\n", got, want)
+	}
+
+	if old, ok := r.PushFront(9); old != 5 || !ok {
+		t.Errorf("PushFront(9) = %d, %t; want 5, true\n", old, ok)
+	}
+	if got, want := contents(r), []int{9, 3, 4}; !cmp.Equal(got, want) {
+		t.Errorf("after PushFront: %+v; want %+v\n", got, want)
+	}
+	if r.Len() != 3 || r.Limit() != 3 {
+		t.Errorf("Len(), Limit() = %d, %d; want 3, 3\n", r.Len(), r.Limit())
+	}
+}
+
+func TestRingPushAfterRotate(t *testing.T) {
+	t.Parallel()
+
+	// Rotating a bounded ring below its limit must leave room to push
+	// without evicting.
+	r := rotate.NewRing[int](4)
+	r.PushBack(1)
+	r.PushBack(2)
+	r.Rotate(1)
+	if _, ok := r.PushBack(3); ok {
+		t.Errorf("PushBack(3) evicted below the limit\n")
+	}
+	r.PushFront(0)
+	if got, want := contents(r), []int{0, 2, 1, 3}; !cmp.Equal(got, want) {
+		t.Errorf("after pushes: %+v; want %+v\n", got, want)
+	}
+	if old, ok := r.PushBack(4); old != 0 || !ok {
+		t.Errorf("PushBack(4) = %d, %t; want 0, true\n", old, ok)
+	}
+
+	u := rotate.NewRing[int](0)
+	for i := 0; i < 5; i++ {
+		u.PushBack(i)
+	}
+	u.Rotate(-2)
+	u.PushBack(5)
+	u.Rotate(1)
+	if got, want := contents(u), []int{4, 0, 1, 2, 5, 3}; !cmp.Equal(got, want) {
+		t.Errorf("unbounded after rotations: %+v; want %+v\n", got, want)
+	}
+}
+
+func TestRingRotateAllocs(t *testing.T) {
+	for _, limit := range []int{0, 16} {
+		r := rotate.NewRing[int](limit)
+		for i := 0; i < 5; i++ {
+			r.PushBack(i)
+		}
+		allocs := testing.AllocsPerRun(100, func() {
+			r.PushBack(5)
+			r.Rotate(2)
+			r.PushFront(6)
+			r.Rotate(-3)
+			r.PopFront()
+			r.PopBack()
+		})
+		if allocs != 0 {
+			t.Errorf("NewRing(%d): %v allocations per push and rotate; want 0\n", limit, allocs)
+		}
+		if r.Len() != 5 {
+			t.Errorf("NewRing(%d): Len() = %d; want 5\n", limit, r.Len())
+		}
+	}
+}
+
+func TestRingZeroValue(t *testing.T) {
+	t.Parallel()
+
+	var r rotate.Ring[string]
+	r.PushBack("b")
+	r.PushFront("a")
+	r.Rotate(1)
+	if got, want := r.Linearize(), []string{"b", "a"}; !cmp.Equal(got, want) {
+		t.Errorf("Linearize() = %+v; want %+v\n", got, want)
+	}
+}
+
+func TestRingAtPanics(t *testing.T) {
+	t.Parallel()
+
+	r := rotate.NewRing[int](2)
+	r.PushBack(1)
+	defer func() {
+		if recover() == nil {
+			t.Errorf("At(1) on a one-element ring did not panic\n")
+		}
+	}()
+	r.At(1)
+}
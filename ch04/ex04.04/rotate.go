@@ -3,7 +3,8 @@ package rotate
 
 // Right rotates a slice of any type rightward by k steps and returns the
 // rotated slice. The function properly handles any k. I.e., k can be zero,
-// larger than the size of the slice, or negative.
+// larger than the size of the slice, or negative. The result is a new slice,
+// and xs is left untouched. To rotate without allocating, use RotateRight.
 func Right[T any](xs []T, k int) []T {
 	// Avoid division by zero, and don't waste time on a singe-item slice.
 	l := len(xs)
@@ -17,13 +18,18 @@ func Right[T any](xs []T, k int) []T {
 	// (see below) that always yields a positive remainder.
 	r := mod((k + l), l)
 
-	return append(xs[l-r:], xs[:l-r]...)
+	// Appending to xs[l-r:] would write past len(xs) into the caller's
+	// backing array whenever cap(xs) > len(xs), so build a new slice.
+	out := make([]T, 0, l)
+	out = append(out, xs[l-r:]...)
+	return append(out, xs[:l-r]...)
 }
 
 // Left rotates a slice of any type leftward by k steps and returns the rotated
 // slice. The function properly handles any k. I.e., k can be zero, larger
-// than the size of the slice, or negative.
-func Left(xs []int, k int) []int {
+// than the size of the slice, or negative. The result is a new slice, and xs
+// is left untouched. To rotate without allocating, use RotateLeft.
+func Left[T any](xs []T, k int) []T {
 	// Avoid division by zero, and don't waste time on a singe-item slice.
 	l := len(xs)
 	if l < 2 {
@@ -36,14 +42,16 @@ func Left(xs []int, k int) []int {
 	// (see below) that always yields a positive remainder.
 	r := mod((k + l), l)
 
-	return append(xs[r:], xs[:r]...)
+	out := make([]T, 0, l)
+	out = append(out, xs[r:]...)
+	return append(out, xs[:r]...)
 }
 
-// ReverseRight rotates a slice of any comparable type rightward by k steps.
+// ReverseRight rotates a slice of any type rightward by k steps.
 // The rotation is done in place, so there is no return value. The function
 // properly handles any k. I.e., k can be zero, larger than the size of the
 // slice, or negative.
-func ReverseRight[T comparable](xs []T, k int) {
+func ReverseRight[T any](xs []T, k int) {
 	// Avoid division by zero, and don't waste time on a singe-item slice.
 	l := len(xs)
 	if l < 2 {
@@ -64,11 +72,11 @@ func ReverseRight[T comparable](xs []T, k int) {
 	reverse(xs[r:])
 }
 
-// ReverseLeft rotates a slice of any comparable type leftward by k steps.
+// ReverseLeft rotates a slice of any type leftward by k steps.
 // The rotation is done in place, so there is no return value. The function
 // properly handles any k. I.e., k can be zero, larger than the size of the
 // slice, or negative.
-func ReverseLeft[T comparable](xs []T, k int) {
+func ReverseLeft[T any](xs []T, k int) {
 	// Avoid division by zero, and don't waste time on a singe-item slice.
 	l := len(xs)
 	if l < 2 {
@@ -97,7 +105,7 @@ func mod(a, b int) int {
 	return (a%b + b) % b
 }
 
-func reverse[T comparable](xs []T) {
+func reverse[T any](xs []T) {
 	for i, j := 0, len(xs)-1; i < j; i, j = i+1, j-1 {
 		xs[i], xs[j] = xs[j], xs[i]
 	}
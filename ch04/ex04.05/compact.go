@@ -0,0 +1,69 @@
+package compact
+
+// Compact replaces each run of equal adjacent elements of s with the first
+// element of the run, in place, and returns the shortened slice.
+func Compact[T comparable](s []T) []T {
+	return CompactFunc(s, func(x T) T { return x })
+}
+
+// CompactFunc is like Compact but treats adjacent elements as equal when key
+// gives them equal keys. The first element of each run is kept.
+func CompactFunc[T any, K comparable](s []T, key func(T) K) []T {
+	if len(s) == 0 {
+		return s
+	}
+
+	n := 1
+	last := key(s[0])
+	for i := 1; i < len(s); i++ {
+		if k := key(s[i]); k != last {
+			s[n] = s[i]
+			n++
+			last = k
+		}
+	}
+
+	return s[:n]
+}
+
+// A Run is a value and the number of adjacent values with the same key that
+// it stands for.
+type Run[T any] struct {
+	Value T
+	Count int
+}
+
+// A Grouper collects a stream of values into runs of adjacent values with
+// equal keys, holding only the current run, so it can compact input of any
+// size.
+type Grouper[T any, K comparable] struct {
+	key  func(T) K
+	run  Run[T]
+	last K
+}
+
+// NewGrouper returns a Grouper that compares values by key.
+func NewGrouper[T any, K comparable](key func(T) K) *Grouper[T, K] {
+	return &Grouper[T, K]{key: key}
+}
+
+// Add adds x to the stream. If x begins a new run, Add returns the run that
+// x ends, and true.
+func (g *Grouper[T, K]) Add(x T) (Run[T], bool) {
+	k := g.key(x)
+	if g.run.Count > 0 && k == g.last {
+		g.run.Count++
+		return Run[T]{}, false
+	}
+	done := g.run
+	g.run, g.last = Run[T]{x, 1}, k
+	return done, done.Count > 0
+}
+
+// Flush returns the current run, and true if there is one, and starts again
+// with an empty stream.
+func (g *Grouper[T, K]) Flush() (Run[T], bool) {
+	done := g.run
+	g.run = Run[T]{}
+	return done, done.Count > 0
+}
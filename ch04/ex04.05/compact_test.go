@@ -0,0 +1,78 @@
+package compact_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/telemachus/gopl/ch04/compact"
+)
+
+func TestCompact(t *testing.T) {
+	tests := map[string]struct {
+		ints     []int
+		expected []int
+	}{
+		"empty slice":  {ints: []int{}, expected: []int{}},
+		"no dups":      {ints: []int{1, 2, 1}, expected: []int{1, 2, 1}},
+		"runs":         {ints: []int{1, 1, 2, 2, 2, 1}, expected: []int{1, 2, 1}},
+		"all the same": {ints: []int{7, 7, 7}, expected: []int{7}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual := compact.Compact(tc.ints)
+			if !reflect.DeepEqual(tc.expected, actual) {
+				t.Errorf("expected %#v; actual %#v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestCompactFunc(t *testing.T) {
+	words := []string{"Go", "go", "GO", "gopher", "Gopher", "go"}
+	expected := []string{"Go", "gopher", "go"}
+
+	actual := compact.CompactFunc(words, strings.ToLower)
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %#v; actual %#v", expected, actual)
+	}
+}
+
+func TestGrouper(t *testing.T) {
+	type person struct {
+		name string
+		city string
+	}
+	people := []person{
+		{"Ann", "Oslo"},
+		{"Bob", "Oslo"},
+		{"Cy", "Rome"},
+		{"Di", "Oslo"},
+		{"Ed", "Oslo"},
+		{"Flo", "Oslo"},
+	}
+	expected := []compact.Run[person]{
+		{Value: person{"Ann", "Oslo"}, Count: 2},
+		{Value: person{"Cy", "Rome"}, Count: 1},
+		{Value: person{"Di", "Oslo"}, Count: 3},
+	}
+
+	g := compact.NewGrouper(func(p person) string { return p.city })
+	var actual []compact.Run[person]
+	for _, p := range people {
+		if run, ok := g.Add(p); ok {
+			actual = append(actual, run)
+		}
+	}
+	if run, ok := g.Flush(); ok {
+		actual = append(actual, run)
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %+v; actual %+v", expected, actual)
+	}
+
+	if run, ok := g.Flush(); ok {
+		t.Errorf("second Flush: expected no run; actual %+v", run)
+	}
+}
@@ -1,8 +1,9 @@
-package ch04_test
+package compact_test
 
 import (
-	"ch04"
 	"testing"
+
+	"github.com/telemachus/gopl/ch04/compact"
 )
 
 func isSameSlice(s1, s2 []string) bool {
@@ -30,7 +31,7 @@ func TestFilterDups(t *testing.T) {
 
 	for name, tc := range tests {
 		t.Run(name, func(t *testing.T) {
-			actual := ch04.FilterDups(tc.strings)
+			actual := compact.FilterDups(tc.strings)
 			if !isSameSlice(tc.expected, actual) {
 				t.Errorf("expected %#v; actual %#v", tc.expected, actual)
 			}
@@ -1,3 +1,3 @@
-module ch04
+module github.com/telemachus/gopl/ch04/compact
 
-go 1.16
+go 1.18
@@ -0,0 +1,131 @@
+// Uniq reports or omits repeated adjacent lines, like the Unix command.
+//
+// Usage:
+//
+//	uniq [-c] [-d | -u] [-i] [-f n] [input [output]]
+//
+// Uniq reads input, or the standard input if input is absent or "-", and
+// writes the first line of each run of equal adjacent lines to output, or to
+// the standard output. It holds only one line at a time, so input can be of
+// any size.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/telemachus/gopl/ch04/compact"
+)
+
+var (
+	count    = flag.Bool("c", false, "precede each line with the number of times it occurred")
+	repeated = flag.Bool("d", false, "print only lines that are repeated")
+	unique   = flag.Bool("u", false, "print only lines that are not repeated")
+	fold     = flag.Bool("i", false, "ignore case when comparing lines")
+	fields   = flag.Int("f", 0, "ignore the first `n` fields of each line when comparing")
+)
+
+func main() {
+	flag.Parse()
+	if flag.NArg() > 2 || *fields < 0 {
+		fmt.Fprintf(os.Stderr, "usage: uniq [flags] [input [output]]\n")
+		flag.PrintDefaults()
+		os.Exit(2)
+	}
+
+	in := os.Stdin
+	if name := flag.Arg(0); name != "" && name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "uniq: %v\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		in = f
+	}
+	out := os.Stdout
+	if name := flag.Arg(1); name != "" && name != "-" {
+		f, err := os.Create(name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "uniq: %v\n", err)
+			os.Exit(1)
+		}
+		out = f
+	}
+
+	w := bufio.NewWriter(out)
+	err := uniq(w, in)
+	if flushErr := w.Flush(); err == nil {
+		err = flushErr
+	}
+	if out != os.Stdout {
+		if closeErr := out.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "uniq: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// uniq copies the runs of lines in r to w as the flags direct.
+func uniq(w io.Writer, r io.Reader) error {
+	g := compact.NewGrouper(key)
+	in := bufio.NewReader(r)
+	for {
+		line, err := in.ReadString('\n')
+		if line != "" {
+			line = strings.TrimSuffix(line, "\n")
+			if run, ok := g.Add(line); ok {
+				if err := write(w, run); err != nil {
+					return err
+				}
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if run, ok := g.Flush(); ok {
+		return write(w, run)
+	}
+	return nil
+}
+
+// key returns the part of line that is compared with its neighbours.
+func key(line string) string {
+	for i := 0; i < *fields; i++ {
+		line = strings.TrimLeft(line, " \t")
+		if j := strings.IndexAny(line, " \t"); j >= 0 {
+			line = line[j:]
+		} else {
+			line = ""
+		}
+	}
+	if *fold {
+		line = strings.ToLower(line)
+	}
+	return line
+}
+
+// write prints run unless -d or -u rules it out.
+func write(w io.Writer, run compact.Run[string]) error {
+	if *repeated && run.Count == 1 || *unique && run.Count > 1 {
+		return nil
+	}
+	var err error
+	if *count {
+		_, err = fmt.Fprintf(w, "%7d %s\n", run.Count, run.Value)
+	} else {
+		_, err = fmt.Fprintln(w, run.Value)
+	}
+	return err
+}
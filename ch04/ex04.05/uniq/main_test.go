@@ -0,0 +1,87 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// setFlags sets the flag variables for one test and restores them after it.
+func setFlags(t *testing.T, c, d, u, i bool, f int) {
+	t.Helper()
+	old := []bool{*count, *repeated, *unique, *fold}
+	oldFields := *fields
+	*count, *repeated, *unique, *fold, *fields = c, d, u, i, f
+	t.Cleanup(func() {
+		*count, *repeated, *unique, *fold = old[0], old[1], old[2], old[3]
+		*fields = oldFields
+	})
+}
+
+func TestKey(t *testing.T) {
+	tests := map[string]struct {
+		fields int
+		fold   bool
+		line   string
+		want   string
+	}{
+		"whole line":            {line: "a b c", want: "a b c"},
+		"skip one field":        {fields: 1, line: "a b c", want: " b c"},
+		"skip two fields":       {fields: 2, line: "a\tb c", want: " c"},
+		"leading blanks":        {fields: 1, line: "  a  b", want: "  b"},
+		"skip past the end":     {fields: 3, line: "a b", want: ""},
+		"fold":                  {fold: true, line: "Hello World", want: "hello world"},
+		"skip a field and fold": {fields: 1, fold: true, line: "1 ABC", want: " abc"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			setFlags(t, false, false, false, tc.fold, tc.fields)
+			if got := key(tc.line); got != tc.want {
+				t.Errorf("key(%q) = %q; want %q", tc.line, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestUniq(t *testing.T) {
+	const input = "a\na\nb\nc\nc\nc\nd"
+
+	tests := map[string]struct {
+		c, d, u bool
+		want    string
+	}{
+		"default":         {want: "a\nb\nc\nd\n"},
+		"count":           {c: true, want: "      2 a\n      1 b\n      3 c\n      1 d\n"},
+		"repeated":        {d: true, want: "a\nc\n"},
+		"unique":          {u: true, want: "b\nd\n"},
+		"count repeated":  {c: true, d: true, want: "      2 a\n      3 c\n"},
+		"repeated unique": {d: true, u: true, want: ""},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			setFlags(t, tc.c, tc.d, tc.u, false, 0)
+			var out strings.Builder
+			if err := uniq(&out, strings.NewReader(input)); err != nil {
+				t.Fatalf("uniq: %v", err)
+			}
+			if got := out.String(); got != tc.want {
+				t.Errorf("got %q; want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestUniqKeyedRuns(t *testing.T) {
+	setFlags(t, true, false, false, true, 1)
+	const input = "1 Apple\n2 apple\n3 pear\n4 PEAR\n5 Pear\n"
+	want := "      2 1 Apple\n      3 3 pear\n"
+
+	var out strings.Builder
+	if err := uniq(&out, strings.NewReader(input)); err != nil {
+		t.Fatalf("uniq: %v", err)
+	}
+	if got := out.String(); got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
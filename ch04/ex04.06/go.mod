@@ -1,3 +1,14 @@
 module ch04
 
 go 1.16
+
+require (
+	github.com/telemachus/gopl/ch04/compact v0.0.0
+	github.com/telemachus/gopl/ch04/reverse v0.0.0
+	golang.org/x/text v0.14.0
+)
+
+replace (
+	github.com/telemachus/gopl/ch04/compact => ../ex04.05
+	github.com/telemachus/gopl/ch04/reverse => ../ex04.07
+)
@@ -0,0 +1,169 @@
+package ch04
+
+import (
+	"bytes"
+	"io"
+	"unicode/utf8"
+
+	"github.com/telemachus/gopl/ch04/reverse"
+	"golang.org/x/text/transform"
+)
+
+// Pipeline returns a reader that streams r through stages in order. Each
+// stage sees its input in chunks, so the whole input never has to fit in
+// memory.
+func Pipeline(r io.Reader, stages ...transform.Transformer) io.Reader {
+	if len(stages) == 0 {
+		return r
+	}
+	return transform.NewReader(r, transform.Chain(stages...))
+}
+
+// squasher does to a stream what SquashByteWith does to a slice.
+type squasher struct {
+	sq squashState
+}
+
+// NewSquasher returns a transformer that replaces each run of whitespace
+// with a single space, as SquashByte does. A rune split between two chunks
+// is decoded once the rest of it arrives.
+func NewSquasher() transform.Transformer {
+	return NewSquasherWith(SquashOptions{})
+}
+
+// NewSquasherWith returns a transformer that collapses whitespace as
+// SquashByteWith does with opts.
+func NewSquasherWith(opts SquashOptions) transform.Transformer {
+	return &squasher{squashState{opts: opts}}
+}
+
+func (t *squasher) Reset() {
+	t.sq = squashState{opts: t.sq.opts}
+}
+
+func (t *squasher) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	for nSrc < len(src) {
+		if !atEOF && !utf8.FullRune(src[nSrc:]) {
+			return nDst, nSrc, transform.ErrShortSrc
+		}
+		r, size := utf8.DecodeRune(src[nSrc:])
+
+		if t.sq.isSpace(r) && (r != utf8.RuneError || size > 1) {
+			t.sq.space(r)
+		} else {
+			rep, n := t.sq.replacement(false)
+			need := n*utf8.RuneLen(rep) + size
+			if nDst+need > len(dst) {
+				return nDst, nSrc, transform.ErrShortDst
+			}
+			t.sq.end(false)
+			nDst = len(appendRunes(dst[:nDst], rep, n))
+			nDst += copy(dst[nDst:], src[nSrc:nSrc+size])
+		}
+		nSrc += size
+	}
+
+	if atEOF {
+		rep, n := t.sq.replacement(true)
+		if nDst+n*utf8.RuneLen(rep) > len(dst) {
+			return nDst, nSrc, transform.ErrShortDst
+		}
+		t.sq.end(true)
+		nDst = len(appendRunes(dst[:nDst], rep, n))
+	}
+	return nDst, nSrc, nil
+}
+
+// lineTransformer collects whole lines, including their newlines, and
+// replaces each with the result of f. A final line without a newline is
+// passed to f at the end of the input.
+type lineTransformer struct {
+	line []byte                   // the current line, until its newline arrives
+	out  []byte                   // output not yet copied to dst
+	f    func(line []byte) []byte // turns a complete line into output
+	init func()                   // resets f's state
+}
+
+func (t *lineTransformer) Reset() {
+	t.line, t.out = t.line[:0], nil
+	if t.init != nil {
+		t.init()
+	}
+}
+
+// flush copies as much pending output as fits into dst and reports whether
+// any is left.
+func (t *lineTransformer) flush(dst []byte, nDst *int) bool {
+	n := copy(dst[*nDst:], t.out)
+	*nDst += n
+	t.out = t.out[n:]
+	return len(t.out) > 0
+}
+
+func (t *lineTransformer) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	for {
+		if t.flush(dst, &nDst) {
+			return nDst, nSrc, transform.ErrShortDst
+		}
+		if nSrc == len(src) {
+			break
+		}
+		i := bytes.IndexByte(src[nSrc:], '\n')
+		if i < 0 {
+			t.line = append(t.line, src[nSrc:]...)
+			nSrc = len(src)
+			break
+		}
+		t.line = append(t.line, src[nSrc:nSrc+i+1]...)
+		nSrc += i + 1
+		t.out = t.f(t.line)
+		t.line = t.line[:0]
+	}
+
+	if atEOF && len(t.line) > 0 {
+		t.out = t.f(t.line)
+		t.line = t.line[:0]
+		if t.flush(dst, &nDst) {
+			return nDst, nSrc, transform.ErrShortDst
+		}
+	}
+	return nDst, nSrc, nil
+}
+
+// NewReverser returns a transformer that reverses the runes of each line
+// with reverse.ReverseByte, leaving line endings in place. Each line is held
+// in memory until its newline arrives.
+func NewReverser() transform.Transformer {
+	return &lineTransformer{f: func(line []byte) []byte {
+		reverse.ReverseByte(trimEOL(line))
+		return line
+	}}
+}
+
+// trimEOL returns line without a trailing "\n" or "\r\n".
+func trimEOL(line []byte) []byte {
+	line = bytes.TrimSuffix(line, []byte("\n"))
+	return bytes.TrimSuffix(line, []byte("\r"))
+}
+
+// NewDupFilter returns a transformer that drops each line that repeats the
+// line before it, as compact.FilterDups drops repeated strings. Lines are
+// compared without their line endings, so a final line without a newline
+// still matches.
+func NewDupFilter() transform.Transformer {
+	var prev []byte
+	seen := false
+	t := &lineTransformer{}
+	t.f = func(line []byte) []byte {
+		body := trimEOL(line)
+		if seen && bytes.Equal(prev, body) {
+			return nil
+		}
+		prev, seen = append(prev[:0], body...), true
+		return line
+	}
+	t.init = func() {
+		prev, seen = prev[:0], false
+	}
+	return t
+}
@@ -0,0 +1,157 @@
+package ch04_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"ch04"
+	"github.com/telemachus/gopl/ch04/compact"
+	"github.com/telemachus/gopl/ch04/reverse"
+	"golang.org/x/text/transform"
+)
+
+// readAll streams s one byte at a time through stages, so that every rune
+// and line is split across reads.
+func readAll(t *testing.T, s string, stages ...transform.Transformer) string {
+	t.Helper()
+	r := ch04.Pipeline(iotest.OneByteReader(strings.NewReader(s)), stages...)
+	b, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("reading pipeline: %v", err)
+	}
+	return string(b)
+}
+
+func TestSquasher(t *testing.T) {
+	inputs := []string{
+		"",
+		"foo  bar",
+		"\t\n   foobar  foo\tbar\\fizz   \nbuzz\n\t  ",
+		"世界　　世界 !",
+		"bad \xff\xfe  bytes\xe4\xb8",
+	}
+
+	for _, s := range inputs {
+		expected := string(ch04.SquashByte([]byte(s)))
+		actual := readAll(t, s, ch04.NewSquasher())
+		if expected != actual {
+			t.Errorf("squashing %q: expected %q; actual %q", s, expected, actual)
+		}
+	}
+}
+
+func TestReverser(t *testing.T) {
+	tests := map[string]struct {
+		s        string
+		expected string
+	}{
+		"empty":                    {s: "", expected: ""},
+		"no newline":               {s: "Hello, 世界!", expected: "!界世 ,olleH"},
+		"lines":                    {s: "abc\n世界\n", expected: "cba\n界世\n"},
+		"crlf stays at end":        {s: "ab\r\ncd", expected: "ba\r\ndc"},
+		"blank lines":              {s: "\n\nab\n", expected: "\n\nba\n"},
+		"invalid bytes stay whole": {s: "a\xffb\n", expected: "b\xffa\n"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual := readAll(t, tc.s, ch04.NewReverser())
+			if tc.expected != actual {
+				t.Errorf("expected %q; actual %q", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestDupFilter(t *testing.T) {
+	tests := map[string]struct {
+		s        string
+		expected string
+	}{
+		"empty":                {s: "", expected: ""},
+		"no dups":              {s: "foo\nbar\n", expected: "foo\nbar\n"},
+		"adjacent dups":        {s: "foo\nfoo\nbar\nfoo\n", expected: "foo\nbar\nfoo\n"},
+		"final line unended":   {s: "foo\nfoo", expected: "foo\n"},
+		"blank lines collapse": {s: "\n\n\nfoo\n", expected: "\nfoo\n"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual := readAll(t, tc.s, ch04.NewDupFilter())
+			if tc.expected != actual {
+				t.Errorf("expected %q; actual %q", tc.expected, actual)
+			}
+		})
+	}
+}
+
+// TestLineStagesMatchHelpers checks that the line stages agree with the
+// slice helpers they are built on.
+func TestLineStagesMatchHelpers(t *testing.T) {
+	inputs := []string{
+		"",
+		"one line",
+		"a\na\nb\nb\nb\na\n",
+		"Hello, 世界!\nHello, 世界!\n\n\nbad \xff\xfe\n",
+	}
+
+	for _, s := range inputs {
+		lines := strings.SplitAfter(s, "\n")
+		if lines[len(lines)-1] == "" {
+			lines = lines[:len(lines)-1]
+		}
+
+		var reversed strings.Builder
+		for _, line := range lines {
+			body := strings.TrimSuffix(line, "\n")
+			reversed.Write(reverse.ReverseByte([]byte(body)))
+			reversed.WriteString(line[len(body):])
+		}
+		if actual := readAll(t, s, ch04.NewReverser()); reversed.String() != actual {
+			t.Errorf("reversing %q: ReverseByte gives %q; NewReverser gives %q", s, reversed.String(), actual)
+		}
+
+		var bodies []string
+		for _, line := range lines {
+			bodies = append(bodies, strings.TrimSuffix(line, "\n"))
+		}
+		expected := strings.Join(compact.FilterDups(bodies), "\n")
+		if strings.HasSuffix(s, "\n") {
+			expected += "\n"
+		}
+		if actual := readAll(t, s, ch04.NewDupFilter()); expected != actual {
+			t.Errorf("filtering %q: FilterDups gives %q; NewDupFilter gives %q", s, expected, actual)
+		}
+	}
+}
+
+func TestPipeline(t *testing.T) {
+	s := "a  b\na  b\n世界\t\tx\n"
+	expected := "b a x 界世 "
+	actual := readAll(t, s, ch04.NewDupFilter(), ch04.NewReverser(), ch04.NewSquasher())
+	if expected != actual {
+		t.Errorf("expected %q; actual %q", expected, actual)
+	}
+
+	if actual := readAll(t, s); s != actual {
+		t.Errorf("with no stages: expected %q; actual %q", s, actual)
+	}
+}
+
+func TestPipelineLargeInput(t *testing.T) {
+	line := strings.Repeat("ab  世界\t", 1000) + "\n"
+	s := strings.Repeat(line, 50)
+
+	r := ch04.Pipeline(strings.NewReader(s), ch04.NewDupFilter(), ch04.NewSquasher())
+	var out bytes.Buffer
+	if _, err := io.Copy(&out, r); err != nil {
+		t.Fatalf("copying pipeline: %v", err)
+	}
+	expected := string(ch04.SquashByte([]byte(line)))
+	if expected != out.String() {
+		t.Errorf("expected %d bytes; actual %d bytes", len(expected), out.Len())
+	}
+}
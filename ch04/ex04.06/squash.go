@@ -5,40 +5,153 @@ import (
 	"unicode/utf8"
 )
 
+// SquashOptions controls how SquashRuneWith and SquashByteWith collapse
+// runs of whitespace. The zero value collapses every run, wherever it is, to
+// one ASCII space, as SquashRune and SquashByte do.
+type SquashOptions struct {
+	// KeepNewlines collapses a run that contains a newline to "\n" instead,
+	// and a run that contains two or more to "\n\n", so that lines and
+	// paragraph breaks survive.
+	KeepNewlines bool
+
+	// Trim drops whitespace at the start and end of the text.
+	Trim bool
+
+	// IsSpace reports which runes count as whitespace. If it is nil,
+	// unicode.IsSpace is used. For example, a function that excludes
+	// U+00A0 keeps no-break spaces as they are.
+	IsSpace func(rune) bool
+
+	// KeepFirst collapses a run to its first rune instead of to ' '.
+	KeepFirst bool
+}
+
 func SquashRune(s string) string {
+	return SquashRuneWith(s, SquashOptions{})
+}
+
+func SquashByte(bytes []byte) []byte {
+	return SquashByteWith(bytes, SquashOptions{})
+}
+
+// SquashRuneWith collapses each run of whitespace in s as opts directs. For
+// valid UTF-8 it gives the same result as SquashByteWith.
+func SquashRuneWith(s string, opts SquashOptions) string {
 	i := 0
 	r := []rune(s)
-	var last rune
+	sq := squashState{opts: opts}
 
 	for _, c := range r {
-		if !unicode.IsSpace(c) {
-			r[i] = c
-			i++
-		} else if unicode.IsSpace(c) && !unicode.IsSpace(last) {
-			r[i] = ' '
+		if sq.isSpace(c) {
+			sq.space(c)
+			continue
+		}
+		rep, n := sq.end(false)
+		for ; n > 0; n-- {
+			r[i] = rep
 			i++
 		}
-		last = c
+		r[i] = c
+		i++
+	}
+	rep, n := sq.end(true)
+	for ; n > 0; n-- {
+		r[i] = rep
+		i++
 	}
 	r = r[:i]
 
 	return string(r)
 }
 
-func SquashByte(bytes []byte) []byte {
+// SquashByteWith collapses each run of whitespace in the UTF-8 text bytes as
+// opts directs, in place, and returns the shortened slice. Invalid bytes are
+// never whitespace and are kept as they are.
+func SquashByteWith(bytes []byte, opts SquashOptions) []byte {
 	out := bytes[:0]
-	var last rune
+	sq := squashState{opts: opts}
 
 	for i := 0; i < len(bytes); {
 		r, s := utf8.DecodeRune(bytes[i:])
 
-		if !unicode.IsSpace(r) {
+		if sq.isSpace(r) && (r != utf8.RuneError || s > 1) {
+			sq.space(r)
+		} else {
+			rep, n := sq.end(false)
+			out = appendRunes(out, rep, n)
 			out = append(out, bytes[i:i+s]...)
-		} else if unicode.IsSpace(r) && !unicode.IsSpace(last) {
-			out = append(out, ' ')
 		}
-		last = r
 		i += s
 	}
-	return out
+	rep, n := sq.end(true)
+	return appendRunes(out, rep, n)
+}
+
+// appendRunes appends n copies of r to b.
+func appendRunes(b []byte, r rune, n int) []byte {
+	var buf [utf8.UTFMax]byte
+	size := utf8.EncodeRune(buf[:], r)
+	for ; n > 0; n-- {
+		b = append(b, buf[:size]...)
+	}
+	return b
+}
+
+// squashState tracks a run of whitespace and decides what replaces it. The
+// slice functions and the squashing transformer share it so that they agree.
+type squashState struct {
+	opts     SquashOptions
+	inRun    bool
+	first    rune // the first rune of the run
+	newlines int  // the number of newlines in the run
+	started  bool // whether anything but whitespace has been seen
+}
+
+func (sq *squashState) isSpace(r rune) bool {
+	if sq.opts.IsSpace != nil {
+		return sq.opts.IsSpace(r)
+	}
+	return unicode.IsSpace(r)
+}
+
+// space adds the whitespace rune r to the current run.
+func (sq *squashState) space(r rune) {
+	if !sq.inRun {
+		sq.inRun, sq.first = true, r
+	}
+	if r == '\n' {
+		sq.newlines++
+	}
+}
+
+// replacement returns what the current run collapses to, as n copies of a
+// rune, given whether the text ends after it. It does not change sq.
+func (sq *squashState) replacement(atEOF bool) (rune, int) {
+	if !sq.inRun {
+		return 0, 0
+	}
+	if sq.opts.Trim && (!sq.started || atEOF) {
+		return 0, 0
+	}
+	if sq.opts.KeepNewlines && sq.newlines > 0 {
+		if sq.newlines > 1 {
+			return '\n', 2
+		}
+		return '\n', 1
+	}
+	if sq.opts.KeepFirst {
+		return sq.first, 1
+	}
+	return ' ', 1
+}
+
+// end finishes the current run and returns its replacement. Unless atEOF is
+// true, a rune other than whitespace follows.
+func (sq *squashState) end(atEOF bool) (rune, int) {
+	r, n := sq.replacement(atEOF)
+	sq.inRun, sq.newlines = false, 0
+	if !atEOF {
+		sq.started = true
+	}
+	return r, n
 }
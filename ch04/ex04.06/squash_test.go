@@ -3,6 +3,7 @@ package ch04_test
 import (
 	"ch04"
 	"testing"
+	"unicode"
 )
 
 var benchString = "\t\n   foobar  foo\tbar\\fizz   \nbuzz\n\t  "
@@ -78,3 +79,75 @@ func BenchmarkSquashByte(b *testing.B) {
 		ch04.SquashByte(benchBytes)
 	}
 }
+
+func noNBSP(r rune) bool {
+	return r != '\u00a0' && unicode.IsSpace(r)
+}
+
+func TestSquashWith(t *testing.T) {
+	tests := map[string]struct {
+		s        string
+		opts     ch04.SquashOptions
+		expected string
+	}{
+		"zero options": {s: " a \t b\n", opts: ch04.SquashOptions{}, expected: " a b "},
+		"keep a newline": {s: "foo  \n  bar", opts: ch04.SquashOptions{KeepNewlines: true}, expected: "foo\nbar"},
+		"keep a paragraph break": {s: "foo \n \n\n bar baz", opts: ch04.SquashOptions{KeepNewlines: true}, expected: "foo\n\nbar baz"},
+		"trim": {s: " \t foo  bar \n", opts: ch04.SquashOptions{Trim: true}, expected: "foo bar"},
+		"trim all space": {s: " \t\n ", opts: ch04.SquashOptions{Trim: true}, expected: ""},
+		"trim keeps inner newlines": {s: "\n\nfoo\n\nbar\n\n", opts: ch04.SquashOptions{Trim: true, KeepNewlines: true}, expected: "foo\n\nbar"},
+		"exclude nbsp": {s: "10\u00a0kg  \u00a0 x", opts: ch04.SquashOptions{IsSpace: noNBSP}, expected: "10\u00a0kg \u00a0 x"},
+		"keep first": {s: "a\t \tb \u3000c\u3000 d", opts: ch04.SquashOptions{KeepFirst: true}, expected: "a\tb c\u3000d"},
+		"keep first and newlines": {s: "a\t\nb\t c", opts: ch04.SquashOptions{KeepFirst: true, KeepNewlines: true}, expected: "a\nb\tc"},
+		"everything": {s: "\u3000 Title\u3000\u3000\n\n  Body\ttext.\n", opts: ch04.SquashOptions{KeepNewlines: true, Trim: true, KeepFirst: true}, expected: "Title\n\nBody\ttext."},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if actual := ch04.SquashRuneWith(tc.s, tc.opts); tc.expected != actual {
+				t.Errorf("SquashRuneWith: expected %q; actual %q", tc.expected, actual)
+			}
+			if actual := string(ch04.SquashByteWith([]byte(tc.s), tc.opts)); tc.expected != actual {
+				t.Errorf("SquashByteWith: expected %q; actual %q", tc.expected, actual)
+			}
+			if actual := readAll(t, tc.s, ch04.NewSquasherWith(tc.opts)); tc.expected != actual {
+				t.Errorf("NewSquasherWith: expected %q; actual %q", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestSquashVariantsAgree(t *testing.T) {
+	pieces := []string{"a", "世", " ", "\t", "\n", "\u00a0", "\u3000", "\r\n"}
+	options := []ch04.SquashOptions{
+		{},
+		{KeepNewlines: true},
+		{Trim: true},
+		{IsSpace: noNBSP},
+		{KeepFirst: true},
+		{KeepNewlines: true, Trim: true, KeepFirst: true, IsSpace: noNBSP},
+	}
+
+	// Try every string of up to four pieces.
+	inputs := []string{""}
+	for n := 0; n < 4; n++ {
+		last := inputs
+		for _, s := range last {
+			for _, p := range pieces {
+				inputs = append(inputs, s+p)
+			}
+		}
+	}
+	for _, opts := range options {
+		for _, s := range inputs {
+			byRune := ch04.SquashRuneWith(s, opts)
+			byByte := string(ch04.SquashByteWith([]byte(s), opts))
+			if byRune != byByte {
+				t.Fatalf("squashing %q with %+v: runes give %q; bytes give %q", s, opts, byRune, byByte)
+			}
+			if streamed := readAll(t, s, ch04.NewSquasherWith(opts)); byByte != streamed {
+				t.Fatalf("squashing %q with %+v: bytes give %q; stream gives %q", s, opts, byByte, streamed)
+			}
+		}
+	}
+}
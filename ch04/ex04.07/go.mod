@@ -1,3 +1,5 @@
-module ch04
+module github.com/telemachus/gopl/ch04/reverse
 
 go 1.16
+
+require github.com/rivo/uniseg v0.4.7
@@ -0,0 +1,24 @@
+package reverse
+
+import "github.com/rivo/uniseg"
+
+// ReverseGraphemes reverses the UTF-8 text b in place by extended grapheme
+// cluster, so that combining marks stay on their letters and emoji sequences
+// such as flags and families stay whole. Like ReverseByte, it reverses the
+// bytes of each cluster and then the whole slice, and it allocates nothing.
+// Invalid bytes are treated as clusters of their own.
+func ReverseGraphemes(b []byte) []byte {
+	rest, state := b, -1
+	for len(rest) > 0 {
+		var cluster []byte
+		cluster, rest, _, state = uniseg.Step(rest, state)
+		rev(cluster)
+	}
+	rev(b)
+	return b
+}
+
+// ReverseGraphemesString returns s reversed by extended grapheme cluster.
+func ReverseGraphemesString(s string) string {
+	return string(ReverseGraphemes([]byte(s)))
+}
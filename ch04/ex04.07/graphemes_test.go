@@ -0,0 +1,105 @@
+package reverse_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/rivo/uniseg"
+	"github.com/telemachus/gopl/ch04/reverse"
+)
+
+func TestReverseGraphemes(t *testing.T) {
+	tests := map[string]struct {
+		s        string
+		expected string
+	}{
+		"empty":                 {s: "", expected: ""},
+		"ascii":                 {s: "Hello", expected: "olleH"},
+		"combining accent":      {s: "cafe\u0301!", expected: "!e\u0301fac"},
+		"precomposed accent":    {s: "café!", expected: "!éfac"},
+		"flags":                 {s: "\U0001F1EB\U0001F1F7\U0001F1E9\U0001F1EA", expected: "\U0001F1E9\U0001F1EA\U0001F1EB\U0001F1F7"},
+		"family emoji":          {s: "a\U0001F468\u200d\U0001F469\u200d\U0001F467b", expected: "b\U0001F468\u200d\U0001F469\u200d\U0001F467a"},
+		"skin tone":             {s: "\U0001F44D\U0001F3FD!", expected: "!\U0001F44D\U0001F3FD"},
+		"hangul jamo":           {s: "\u1100\u1161\u11a8x", expected: "x\u1100\u1161\u11a8"},
+		"crlf stays together":   {s: "a\r\nb", expected: "b\r\na"},
+		"devanagari":            {s: "नमस्ते", expected: "तेस्मन"},
+		"invalid bytes":         {s: "a\xffb", expected: "b\xffa"},
+		"same as runes for cjk": {s: "Hello, 世界!", expected: "!界世 ,olleH"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual := string(reverse.ReverseGraphemes([]byte(tc.s)))
+			if tc.expected != actual {
+				t.Errorf("expected %q; actual %q", tc.expected, actual)
+			}
+			if actual := reverse.ReverseGraphemesString(tc.s); tc.expected != actual {
+				t.Errorf("ReverseGraphemesString: expected %q; actual %q", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestReverseGraphemesAllocs(t *testing.T) {
+	b := []byte("cafe\u0301 \U0001F1EB\U0001F1F7 \U0001F468\u200d\U0001F469\u200d\U0001F467")
+	allocs := testing.AllocsPerRun(100, func() {
+		reverse.ReverseGraphemes(b)
+	})
+	if allocs != 0 {
+		t.Errorf("expected 0 allocations; actual %v", allocs)
+	}
+}
+
+// clusters splits s into extended grapheme clusters.
+func clusters(s string) []string {
+	var cs []string
+	g := uniseg.NewGraphemes(s)
+	for g.Next() {
+		cs = append(cs, g.Str())
+	}
+	return cs
+}
+
+func FuzzReverseGraphemes(f *testing.F) {
+	for _, s := range []string{
+		"",
+		"Hello, 世界!",
+		"cafe\u0301",
+		"\U0001F1EB\U0001F1F7\U0001F1E9",
+		"\U0001F468\u200d\U0001F469\u200d\U0001F467",
+		"\u0301a",
+		"a\r\n\xff",
+	} {
+		f.Add(s)
+	}
+
+	f.Fuzz(func(t *testing.T, s string) {
+		b := []byte(s)
+		rev := string(reverse.ReverseGraphemes(b))
+
+		// The result is the original's clusters in reverse order.
+		cs := clusters(s)
+		var want bytes.Buffer
+		for i := len(cs) - 1; i >= 0; i-- {
+			want.WriteString(cs[i])
+		}
+		if rev != want.String() {
+			t.Fatalf("ReverseGraphemes(%q) = %q; want %q", s, rev, want.String())
+		}
+
+		// Reversal moves clusters, and a cluster can join its new neighbours:
+		// "\u0301a" reversed is "a\u0301", a single cluster. Reversing twice
+		// gives back the original whenever the reversed text splits into
+		// the original's clusters in reverse order, which is so for text
+		// whose clusters all begin with a base character.
+		rcs := clusters(rev)
+		for i := range rcs {
+			if len(rcs) != len(cs) || rcs[i] != cs[len(cs)-1-i] {
+				t.Skip("reversal regroups clusters")
+			}
+		}
+		if again := string(reverse.ReverseGraphemes(b)); again != s {
+			t.Fatalf("reversing %q twice gave %q", s, again)
+		}
+	})
+}
@@ -1,4 +1,4 @@
-package ch04
+package reverse
 
 import "unicode/utf8"
 
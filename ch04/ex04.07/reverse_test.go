@@ -1,8 +1,9 @@
-package ch04_test
+package reverse_test
 
 import (
-	"ch04"
 	"testing"
+
+	"github.com/telemachus/gopl/ch04/reverse"
 )
 
 var phrase = "Hello, 世界!"
@@ -10,7 +11,7 @@ var reversePhrase = "!界世 ,olleH"
 
 func TestReverseRune(t *testing.T) {
 	expected := reversePhrase
-	actual := string(ch04.ReverseRune([]rune(phrase)))
+	actual := string(reverse.ReverseRune([]rune(phrase)))
 
 	if expected != actual {
 		t.Errorf("expected %q; actual %q", expected, actual)
@@ -19,7 +20,7 @@ func TestReverseRune(t *testing.T) {
 
 func TestReverseByte(t *testing.T) {
 	expected := reversePhrase
-	actual := string(ch04.ReverseByte([]byte(phrase)))
+	actual := string(reverse.ReverseByte([]byte(phrase)))
 
 	if expected != actual {
 		t.Errorf("expected %q; actual %q", expected, actual)
@@ -29,13 +30,13 @@ func TestReverseByte(t *testing.T) {
 func BenchmarkReverseRune(b *testing.B) {
 	r := []rune(phrase)
 	for i := 0; i < b.N; i++ {
-		ch04.ReverseRune(r)
+		reverse.ReverseRune(r)
 	}
 }
 
 func BenchmarkReverseByte(b *testing.B) {
 	bytes := []byte(phrase)
 	for i := 0; i < b.N; i++ {
-		ch04.ReverseByte(bytes)
+		reverse.ReverseByte(bytes)
 	}
 }
@@ -2,47 +2,83 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
 	"io"
 	"os"
-	"unicode"
+
+	"gopl/ch11/char"
+)
+
+var (
+	asJSON  = flag.Bool("json", false, "print the profile as JSON")
+	offsets = flag.Bool("offsets", false, "report the byte offset and length of each invalid UTF-8 sequence on stderr")
+	repair  = flag.String("repair", "", "write a copy of the input to `file`, with invalid UTF-8 replaced by U+FFFD")
 )
 
 func main() {
-	countsByType := make(map[string]int)
-	invalid := 0
+	flag.Parse()
+
+	p := char.NewProfile()
+	if err := count(p, os.Stdin); err != nil {
+		fmt.Fprintf(os.Stderr, "charcount: %v\n", err)
+		os.Exit(1)
+	}
 
-	in := bufio.NewReader(os.Stdin)
+	var err error
+	if *asJSON {
+		err = p.WriteJSON(os.Stdout)
+	} else {
+		err = p.WriteTable(os.Stdout)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "charcount: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// count adds the runes in r to p. Depending on the flags, it also reports
+// invalid sequences and writes a repaired copy of the input.
+func count(p *char.Profile, r io.Reader) error {
+	if !*offsets && *repair == "" {
+		_, err := p.ReadFrom(r)
+		return err
+	}
+
+	var out *bufio.Writer
+	if *repair != "" {
+		f, err := os.Create(*repair)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = bufio.NewWriter(f)
+	}
+
+	d := char.NewDecoder(r)
 	for {
-		r, _, err := in.ReadRune()
+		c, _, err := d.ReadRune()
 		if err == io.EOF {
 			break
 		}
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "charcount: %v\n", err)
-			os.Exit(1)
+		if e, ok := err.(*char.InvalidError); ok {
+			p.Invalid++
+			if *offsets {
+				fmt.Fprintf(os.Stderr, "charcount: %v\n", e)
+			}
+		} else if err != nil {
+			return err
+		} else {
+			p.Add(c)
 		}
-
-		switch {
-		case r == unicode.ReplacementChar:
-			invalid++
-			continue
-		case unicode.IsDigit(r):
-			countsByType["digit"]++
-		case unicode.IsLetter(r):
-			countsByType["letter"]++
-		case unicode.IsPunct(r):
-			countsByType["punctuation"]++
-		case unicode.IsSpace(r):
-			countsByType["whitespace"]++
+		if out != nil {
+			if _, err := out.WriteRune(c); err != nil {
+				return err
+			}
 		}
 	}
-
-	fmt.Println("Characters by type:")
-	for t, n := range countsByType {
-		fmt.Printf("%25s\t%d\n", t, n)
-	}
-	if invalid > 0 {
-		fmt.Printf("\n%d invalid UTF-8 characters\n", invalid)
+	if out != nil {
+		return out.Flush()
 	}
+	return nil
 }
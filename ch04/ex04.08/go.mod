@@ -1,3 +1,7 @@
 module charcount
 
 go 1.16
+
+require gopl/ch11/char v0.0.0
+
+replace gopl/ch11/char => ../../ch11/char
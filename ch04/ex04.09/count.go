@@ -0,0 +1,175 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// A tally holds the n-gram counts of one or more documents. Its n-grams are
+// words joined by single spaces; the 1-grams are the words themselves.
+// N-grams do not span documents, and stop words are dropped before n-grams
+// are formed.
+type tally struct {
+	grams []map[string]int // grams[n-1] counts the n-grams
+	total []int            // total[n-1] is the number of n-grams counted
+}
+
+// newTally returns an empty tally of n-grams for n from 1 to maxN.
+func newTally(maxN int) *tally {
+	t := &tally{grams: make([]map[string]int, maxN), total: make([]int, maxN)}
+	for i := range t.grams {
+		t.grams[i] = make(map[string]int)
+	}
+	return t
+}
+
+// add counts the n-grams of the document r.
+func (t *tally) add(tok *tokenizer, r io.Reader) error {
+	window := make([]string, 0, len(t.grams))
+	return tok.words(r, func(w string) {
+		if len(window) == cap(window) {
+			copy(window, window[1:])
+			window = window[:len(window)-1]
+		}
+		window = append(window, w)
+		for n := 1; n <= len(window); n++ {
+			g := w
+			if n > 1 {
+				g = strings.Join(window[len(window)-n:], " ")
+			}
+			t.grams[n-1][g]++
+			t.total[n-1]++
+		}
+	})
+}
+
+// merge adds the counts in o to t, which must count the same n-grams.
+func (t *tally) merge(o *tally) {
+	for i := range t.grams {
+		a, b := t.grams[i], o.grams[i]
+		if len(a) < len(b) {
+			a, b = b, a
+		}
+		for g, c := range b {
+			a[g] += c
+		}
+		t.grams[i] = a
+		t.total[i] += o.total[i]
+	}
+}
+
+// countOptions controls countFiles.
+type countOptions struct {
+	workers int             // files counted at once
+	stop    map[string]bool // words to drop
+	maxN    int             // count n-grams for n from 1 to maxN
+	perDoc  bool            // keep a separate tally for each file
+}
+
+// countFiles counts the n-grams in the named files, where "-" is the
+// standard input. Unless opts.perDoc is set, each worker adds its files into
+// one tally, the tallies are merged at the end, and countFiles returns the
+// result alone. With opts.perDoc, the tallies are returned in the order of
+// names. Files that cannot be read are skipped, leaving a nil tally in
+// per-document results, and their errors returned; merged results keep
+// whatever was read before an error.
+func countFiles(names []string, opts countOptions) ([]*tally, []error) {
+	if opts.maxN < 1 {
+		opts.maxN = 1
+	}
+	docs := make([]*tally, len(names))
+	var mu sync.Mutex
+	var accs []*tally
+	errs := eachFile(names, opts.workers, func() fileFunc {
+		tok := newTokenizer(opts.stop)
+		acc := newTally(opts.maxN)
+		mu.Lock()
+		accs = append(accs, acc)
+		mu.Unlock()
+		return func(i int, r io.Reader) error {
+			if !opts.perDoc {
+				return acc.add(tok, r)
+			}
+			t := newTally(opts.maxN)
+			if err := t.add(tok, r); err != nil {
+				return err
+			}
+			docs[i] = t
+			return nil
+		}
+	})
+
+	if opts.perDoc {
+		return docs, errs
+	}
+	total := newTally(opts.maxN)
+	for _, acc := range accs {
+		total.merge(acc)
+	}
+	return []*tally{total}, errs
+}
+
+// A fileFunc processes the contents of the i-th file.
+type fileFunc func(i int, r io.Reader) error
+
+// eachFile processes the named files, where "-" is the standard input and may
+// appear only once, on up to workers goroutines. Each goroutine calls start
+// once to get a fileFunc of its own, which can keep state without locking,
+// and then calls it for each of its share of the files. Files that cannot be
+// opened or read are skipped, and their errors returned. When eachFile
+// returns, every fileFunc has finished.
+func eachFile(names []string, workers int, start func() fileFunc) []error {
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(names) {
+		workers = len(names)
+	}
+
+	type job struct {
+		i    int
+		name string
+	}
+	jobs := make(chan job)
+	var mu sync.Mutex
+	var errs []error
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			f := start()
+			for j := range jobs {
+				if err := processFile(f, j.i, j.name); err != nil {
+					mu.Lock()
+					errs = append(errs, err)
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for i, name := range names {
+		jobs <- job{i, name}
+	}
+	close(jobs)
+	wg.Wait()
+	return errs
+}
+
+func processFile(f fileFunc, i int, name string) error {
+	if name == "-" {
+		return f(i, os.Stdin)
+	}
+	file, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if err := f(i, file); err != nil {
+		return fmt.Errorf("%s: %v", name, err)
+	}
+	return nil
+}
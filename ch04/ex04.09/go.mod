@@ -1,3 +1,8 @@
 module wordfreq
 
 go 1.16
+
+require (
+	github.com/rivo/uniseg v0.4.7
+	golang.org/x/text v0.14.0
+)
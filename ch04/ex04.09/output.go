@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// writeText writes recs in the traditional wordfreq layout, with the score
+// before the count and the error after it where there are any, and a header
+// before each document's records.
+func writeText(w io.Writer, recs []record) error {
+	doc := ""
+	for i, r := range recs {
+		if r.Doc != doc {
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+			doc = r.Doc
+			fmt.Fprintf(w, "==> %s <==\n", doc)
+		}
+		var err error
+		switch {
+		case r.Score != nil:
+			_, err = fmt.Fprintf(w, "%10.4f %8d: %s\n", *r.Score, r.Count, r.Term)
+		case r.Error != nil:
+			_, err = fmt.Fprintf(w, "%8d ±%-6d: %s\n", r.Count, *r.Error, r.Term)
+		default:
+			_, err = fmt.Fprintf(w, "%8d: %s\n", r.Count, r.Term)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeCSV writes recs as CSV with a header row. The doc, error and score
+// columns appear only if some record has them.
+func writeCSV(w io.Writer, recs []record) error {
+	var hasDoc, hasError, hasScore bool
+	for _, r := range recs {
+		hasDoc = hasDoc || r.Doc != ""
+		hasError = hasError || r.Error != nil
+		hasScore = hasScore || r.Score != nil
+	}
+
+	cw := csv.NewWriter(w)
+	row := func(doc, term, count, errBound, score string) []string {
+		var fields []string
+		if hasDoc {
+			fields = append(fields, doc)
+		}
+		fields = append(fields, term, count)
+		if hasError {
+			fields = append(fields, errBound)
+		}
+		if hasScore {
+			fields = append(fields, score)
+		}
+		return fields
+	}
+	cw.Write(row("doc", "term", "count", "error", "score"))
+	for _, r := range recs {
+		errBound, score := "", ""
+		if r.Error != nil {
+			errBound = strconv.Itoa(*r.Error)
+		}
+		if r.Score != nil {
+			score = strconv.FormatFloat(*r.Score, 'g', -1, 64)
+		}
+		cw.Write(row(r.Doc, r.Term, strconv.Itoa(r.Count), errBound, score))
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeJSON writes recs as an indented JSON array.
+func writeJSON(w io.Writer, recs []record) error {
+	if recs == nil {
+		recs = []record{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(recs)
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"unicode"
+
+	"github.com/rivo/uniseg"
+	"golang.org/x/text/cases"
+)
+
+// A tokenizer splits text into words by the Unicode word-boundary rules of
+// UAX #29, so "Go," and "go" both give "go" and "don't" stays one word.
+// Segments without a letter or digit, such as spaces and punctuation, are
+// dropped, and the rest are case folded. A tokenizer is not safe for
+// concurrent use.
+type tokenizer struct {
+	fold cases.Caser
+	stop map[string]bool // folded words to drop
+}
+
+func newTokenizer(stop map[string]bool) *tokenizer {
+	return &tokenizer{fold: cases.Fold(), stop: stop}
+}
+
+// words calls emit with each word of r in order. Words never span lines, so
+// r is read a line at a time and may be of any size.
+func (t *tokenizer) words(r io.Reader, emit func(word string)) error {
+	in := bufio.NewReader(r)
+	for {
+		line, err := in.ReadString('\n')
+		for state := -1; line != ""; {
+			var seg string
+			seg, line, state = uniseg.FirstWordInString(line, state)
+			if !isWord(seg) {
+				continue
+			}
+			w := t.fold.String(seg)
+			if !t.stop[w] {
+				emit(w)
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// isWord reports whether seg contains a letter or a digit.
+func isWord(seg string) bool {
+	for _, r := range seg {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			return true
+		}
+	}
+	return false
+}
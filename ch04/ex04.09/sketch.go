@@ -0,0 +1,342 @@
+package main
+
+import (
+	"container/heap"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"sort"
+	"sync"
+)
+
+// A countMin is a Count-Min Sketch: a Depth × Width table of counters in
+// which each word increments one counter per row. A word's estimate is the
+// least of its counters. It never undercounts, and with probability at least
+// 1-δ it overcounts by at most εN, where N is the number of words added, if
+// Width = ⌈e/ε⌉ and Depth = ⌈ln(1/δ)⌉. Its size is fixed however many
+// distinct words it sees.
+type countMin struct {
+	Width, Depth int
+	Table        []uint64 // Depth rows of Width counters
+	Total        uint64   // words added
+}
+
+func newCountMin(eps, delta float64) *countMin {
+	w := int(math.Ceil(math.E / eps))
+	d := int(math.Ceil(math.Log(1 / delta)))
+	if d < 1 {
+		d = 1
+	}
+	return &countMin{Width: w, Depth: d, Table: make([]uint64, w*d)}
+}
+
+// hashes returns two independent 64-bit hashes of w. Row i uses h1 + i·h2,
+// which is as good as Depth separate hash functions. The hashes depend only
+// on w, so sketches built by different processes can be merged.
+func hashes(w string) (h1, h2 uint64) {
+	h := fnv.New64a()
+	io.WriteString(h, w)
+	h1 = h.Sum64()
+	h2 = mix(h1) | 1
+	return h1, h2
+}
+
+// mix scrambles x with the SplitMix64 finalizer.
+func mix(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+func (c *countMin) add(w string, n uint64) {
+	h1, h2 := hashes(w)
+	for i := 0; i < c.Depth; i++ {
+		j := (h1 + uint64(i)*h2) % uint64(c.Width)
+		c.Table[i*c.Width+int(j)] += n
+	}
+	c.Total += n
+}
+
+func (c *countMin) estimate(w string) uint64 {
+	h1, h2 := hashes(w)
+	est := uint64(math.MaxUint64)
+	for i := 0; i < c.Depth; i++ {
+		j := (h1 + uint64(i)*h2) % uint64(c.Width)
+		if v := c.Table[i*c.Width+int(j)]; v < est {
+			est = v
+		}
+	}
+	return est
+}
+
+// merge adds the counts of o to c. Both must have the same shape.
+func (c *countMin) merge(o *countMin) error {
+	if c.Width != o.Width || c.Depth != o.Depth {
+		return errors.New("count-min sketches have different error bounds")
+	}
+	for i, v := range o.Table {
+		c.Table[i] += v
+	}
+	c.Total += o.Total
+	return nil
+}
+
+// An ssEntry is a word monitored by a spaceSaving summary. Its true count
+// lies between Count-Err and Count.
+type ssEntry struct {
+	Word       string
+	Count, Err uint64
+}
+
+// A spaceSaving summary finds the heavy hitters of a stream with a fixed
+// number of counters, by the Space-Saving algorithm of Metwally, Agrawal and
+// El Abbadi. When a new word arrives and every counter is taken, the word
+// replaces the least-counted one and inherits its count as its error. With
+// Cap counters every word occurring more than N/Cap times is monitored, and
+// no count is off by more than N/Cap.
+type spaceSaving struct {
+	Cap     int
+	Entries []ssEntry      // a min-heap by Count
+	index   map[string]int // word -> position in Entries
+}
+
+func newSpaceSaving(capacity int) *spaceSaving {
+	return &spaceSaving{Cap: capacity, index: make(map[string]int)}
+}
+
+func (s *spaceSaving) Len() int           { return len(s.Entries) }
+func (s *spaceSaving) Less(i, j int) bool { return s.Entries[i].Count < s.Entries[j].Count }
+func (s *spaceSaving) Swap(i, j int) {
+	s.Entries[i], s.Entries[j] = s.Entries[j], s.Entries[i]
+	s.index[s.Entries[i].Word] = i
+	s.index[s.Entries[j].Word] = j
+}
+func (s *spaceSaving) Push(x interface{}) {
+	e := x.(ssEntry)
+	s.index[e.Word] = len(s.Entries)
+	s.Entries = append(s.Entries, e)
+}
+func (s *spaceSaving) Pop() interface{} {
+	e := s.Entries[len(s.Entries)-1]
+	s.Entries = s.Entries[:len(s.Entries)-1]
+	delete(s.index, e.Word)
+	return e
+}
+
+func (s *spaceSaving) add(w string, n uint64) {
+	if i, ok := s.index[w]; ok {
+		s.Entries[i].Count += n
+		heap.Fix(s, i)
+		return
+	}
+	if len(s.Entries) < s.Cap {
+		heap.Push(s, ssEntry{w, n, 0})
+		return
+	}
+	min := s.Entries[0]
+	delete(s.index, min.Word)
+	s.Entries[0] = ssEntry{w, min.Count + n, min.Count}
+	s.index[w] = 0
+	heap.Fix(s, 0)
+}
+
+// floor returns the most that a word the summary does not monitor can have
+// occurred: the least count if every counter is taken, and otherwise 0.
+func (s *spaceSaving) floor() uint64 {
+	if len(s.Entries) < s.Cap {
+		return 0
+	}
+	return s.Entries[0].Count
+}
+
+// merge combines o into s as Agarwal et al. do for mergeable summaries: a
+// word missing from one summary is taken to have that summary's floor as
+// both its count and its error, and the Cap highest counts are kept. The
+// error bound of the result is that of one summary of both streams.
+func (s *spaceSaving) merge(o *spaceSaving) {
+	f1, f2 := s.floor(), o.floor()
+	all := make(map[string]ssEntry)
+	for _, e := range s.Entries {
+		e.Count += f2
+		e.Err += f2
+		all[e.Word] = e
+	}
+	for _, e := range o.Entries {
+		if prev, ok := all[e.Word]; ok {
+			prev.Count = prev.Count - f2 + e.Count
+			prev.Err = prev.Err - f2 + e.Err
+			all[e.Word] = prev
+			continue
+		}
+		e.Count += f1
+		e.Err += f1
+		all[e.Word] = e
+	}
+
+	entries := make([]ssEntry, 0, len(all))
+	for _, e := range all {
+		entries = append(entries, e)
+	}
+	sortEntries(entries)
+	if len(entries) > s.Cap {
+		entries = entries[:s.Cap]
+	}
+	s.Entries = entries
+	s.index = make(map[string]int, len(entries))
+	for i, e := range entries {
+		s.index[e.Word] = i
+	}
+	heap.Init(s)
+}
+
+// sortEntries sorts entries by count, highest first, then by word.
+func sortEntries(entries []ssEntry) {
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Count != entries[j].Count {
+			return entries[i].Count > entries[j].Count
+		}
+		return entries[i].Word < entries[j].Word
+	})
+}
+
+// A sketch summarizes a stream of words in bounded memory. Its two parts
+// catch each other's errors: Space-Saving names the frequent words and bounds
+// their counts from below, and Count-Min tightens the bound from above.
+// Sketches made with the same error bounds can be saved, and merged across
+// shards of a corpus.
+type sketch struct {
+	Eps, Delta float64
+	CM         *countMin
+	SS         *spaceSaving
+}
+
+// newSketch returns a sketch whose counts are off by at most eps times the
+// number of words, and by that much for Count-Min only with probability
+// 1-delta.
+func newSketch(eps, delta float64) *sketch {
+	return &sketch{
+		Eps:   eps,
+		Delta: delta,
+		CM:    newCountMin(eps, delta),
+		SS:    newSpaceSaving(int(math.Ceil(1 / eps))),
+	}
+}
+
+func (s *sketch) add(w string) {
+	s.CM.add(w, 1)
+	s.SS.add(w, 1)
+}
+
+func (s *sketch) merge(o *sketch) error {
+	if s.Eps != o.Eps || s.Delta != o.Delta {
+		return errors.New("cannot merge sketches with different error bounds")
+	}
+	if err := s.CM.merge(o.CM); err != nil {
+		return err
+	}
+	s.SS.merge(o.SS)
+	return nil
+}
+
+// top returns estimates for the k most frequent words, or for every word the
+// sketch monitors if k is zero or less. Each record's Count is an upper
+// bound on the word's true count and its Error the width of the range the
+// true count lies in.
+func (s *sketch) top(k int) []record {
+	recs := make([]record, 0, len(s.SS.Entries))
+	for _, e := range s.SS.Entries {
+		est := e.Count
+		if cm := s.CM.estimate(e.Word); cm < est {
+			est = cm
+		}
+		errBound := int(est - (e.Count - e.Err))
+		recs = append(recs, record{Term: e.Word, Count: int(est), Error: &errBound})
+	}
+	sort.Slice(recs, func(i, j int) bool {
+		if recs[i].Count != recs[j].Count {
+			return recs[i].Count > recs[j].Count
+		}
+		return recs[i].Term < recs[j].Term
+	})
+	if k > 0 && k < len(recs) {
+		recs = recs[:k]
+	}
+	return recs
+}
+
+// sketchFiles builds a sketch of the words in the named files, where "-" is
+// the standard input. Each worker sketches its share of the files, and the
+// sketches are merged at the end. Files that cannot be read are skipped, and
+// their errors returned.
+func sketchFiles(names []string, workers int, stop map[string]bool, eps, delta float64) (*sketch, []error) {
+	var mu sync.Mutex
+	var shards []*sketch
+	errs := eachFile(names, workers, func() fileFunc {
+		tok := newTokenizer(stop)
+		sk := newSketch(eps, delta)
+		mu.Lock()
+		shards = append(shards, sk)
+		mu.Unlock()
+		return func(_ int, r io.Reader) error {
+			return tok.words(r, sk.add)
+		}
+	})
+
+	total := newSketch(eps, delta)
+	for _, sk := range shards {
+		total.merge(sk) // same bounds, so merge cannot fail
+	}
+	return total, errs
+}
+
+// save writes s to w for a later merge.
+func (s *sketch) save(w io.Writer) error {
+	return gob.NewEncoder(w).Encode(s)
+}
+
+// loadSketch reads a sketch written by save. It rejects a sketch whose parts
+// are missing or out of shape, which would otherwise make add, estimate or
+// merge index out of range.
+func loadSketch(r io.Reader) (*sketch, error) {
+	var s sketch
+	if err := gob.NewDecoder(r).Decode(&s); err != nil {
+		return nil, err
+	}
+	if err := s.check(); err != nil {
+		return nil, err
+	}
+	s.SS.index = make(map[string]int, len(s.SS.Entries))
+	for i, e := range s.SS.Entries {
+		if _, ok := s.SS.index[e.Word]; ok {
+			return nil, fmt.Errorf("corrupt sketch: %q is monitored twice", e.Word)
+		}
+		s.SS.index[e.Word] = i
+	}
+	heap.Init(s.SS)
+	return &s, nil
+}
+
+// check reports whether s has the shape that its methods rely on.
+func (s *sketch) check() error {
+	switch {
+	case s.CM == nil:
+		return errors.New("corrupt sketch: no count-min table")
+	case s.SS == nil:
+		return errors.New("corrupt sketch: no space-saving summary")
+	case s.CM.Width < 1 || s.CM.Depth < 1:
+		return fmt.Errorf("corrupt sketch: count-min table is %d×%d", s.CM.Depth, s.CM.Width)
+	case len(s.CM.Table)%s.CM.Width != 0 || len(s.CM.Table)/s.CM.Width != s.CM.Depth:
+		return fmt.Errorf("corrupt sketch: count-min table has %d counters, not %d×%d",
+			len(s.CM.Table), s.CM.Depth, s.CM.Width)
+	case s.SS.Cap < 1 || len(s.SS.Entries) > s.SS.Cap:
+		return fmt.Errorf("corrupt sketch: space-saving summary has %d entries for %d counters",
+			len(s.SS.Entries), s.SS.Cap)
+	}
+	return nil
+}
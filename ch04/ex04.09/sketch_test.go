@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// zipfStream returns n words drawn from a Zipf distribution over a large
+// vocabulary, and their true counts.
+func zipfStream(seed int64, n int) ([]string, map[string]int) {
+	z := rand.NewZipf(rand.New(rand.NewSource(seed)), 1.2, 1, 100000)
+	words := make([]string, n)
+	counts := make(map[string]int)
+	for i := range words {
+		w := fmt.Sprintf("w%d", z.Uint64())
+		words[i] = w
+		counts[w]++
+	}
+	return words, counts
+}
+
+// checkBounds checks that every record's range contains the true count and
+// is no wider than eps·n.
+func checkBounds(t *testing.T, recs []record, counts map[string]int, eps float64, n int) {
+	t.Helper()
+	limit := int(eps * float64(n))
+	for _, r := range recs {
+		lower := r.Count - *r.Error
+		if c := counts[r.Term]; c < lower || c > r.Count {
+			t.Errorf("%s: true count %d outside [%d, %d]", r.Term, c, lower, r.Count)
+		}
+		if *r.Error > limit {
+			t.Errorf("%s: error %d exceeds bound %d", r.Term, *r.Error, limit)
+		}
+	}
+}
+
+func TestSketchBounds(t *testing.T) {
+	const eps, n = 0.001, 200000
+	words, counts := zipfStream(1, n)
+	sk := newSketch(eps, 0.001)
+	for _, w := range words {
+		sk.add(w)
+	}
+
+	for w, c := range counts {
+		if est := sk.CM.estimate(w); est < uint64(c) {
+			t.Fatalf("count-min estimate %d of %s is below true count %d", est, w, c)
+		}
+	}
+
+	recs := sk.top(20)
+	checkBounds(t, recs, counts, eps, n)
+	exact := topK(counts, 20)
+	for i := range exact[:10] {
+		if recs[i].Term != exact[i].word {
+			t.Errorf("rank %d: expected %s; actual %s", i, exact[i].word, recs[i].Term)
+		}
+	}
+}
+
+func TestSketchMerge(t *testing.T) {
+	const eps = 0.001
+	var all []string
+	counts := make(map[string]int)
+	total := newSketch(eps, 0.01)
+	for seed := int64(1); seed <= 4; seed++ {
+		words, _ := zipfStream(seed, 50000)
+		shard := newSketch(eps, 0.01)
+		for _, w := range words {
+			shard.add(w)
+			counts[w]++
+		}
+		all = append(all, words...)
+		if err := total.merge(shard); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if total.CM.Total != uint64(len(all)) {
+		t.Errorf("merged total: expected %d; actual %d", len(all), total.CM.Total)
+	}
+	checkBounds(t, total.top(0), counts, eps, len(all))
+
+	if err := total.merge(newSketch(0.01, 0.01)); err == nil {
+		t.Errorf("merging sketches with different bounds: expected an error")
+	}
+}
+
+func TestSketchSaveLoad(t *testing.T) {
+	sk := newSketch(0.01, 0.01)
+	tok := newTokenizer(nil)
+	if err := tok.words(strings.NewReader("a b a c a b"), sk.add); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := sk.save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := loadSketch(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded.add("b")
+
+	var text bytes.Buffer
+	if err := writeText(&text, loaded.top(2)); err != nil {
+		t.Fatal(err)
+	}
+	expected := "       3 ±0     : a\n       3 ±0     : b\n"
+	if actual := text.String(); expected != actual {
+		t.Errorf("expected %q; actual %q", expected, actual)
+	}
+}
+
+func TestLoadSketchRejectsDamage(t *testing.T) {
+	tests := map[string]func(sk *sketch){
+		"no count-min table":      func(sk *sketch) { sk.CM = nil },
+		"no space-saving summary": func(sk *sketch) { sk.SS = nil },
+		"zero width":              func(sk *sketch) { sk.CM.Width = 0 },
+		"short table":             func(sk *sketch) { sk.CM.Table = sk.CM.Table[:len(sk.CM.Table)-1] },
+		"extra row":               func(sk *sketch) { sk.CM.Depth++ },
+		"zero capacity":           func(sk *sketch) { sk.SS.Cap = 0 },
+		"too many entries":        func(sk *sketch) { sk.SS.Cap = 1 },
+		"repeated entry":          func(sk *sketch) { sk.SS.Entries[1].Word = sk.SS.Entries[0].Word },
+	}
+
+	for name, damage := range tests {
+		t.Run(name, func(t *testing.T) {
+			sk := newSketch(0.01, 0.01)
+			for _, w := range []string{"a", "b", "a", "c"} {
+				sk.add(w)
+			}
+			damage(sk)
+			var buf bytes.Buffer
+			if err := sk.save(&buf); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := loadSketch(&buf); err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+// A record is one line of output: a term, how often it occurred, and for
+// some modes a score, the document it belongs to, or how far an estimated
+// count may be from the truth.
+type record struct {
+	Doc   string   `json:"doc,omitempty"`
+	Term  string   `json:"term"`
+	Count int      `json:"count"`
+	Error *int     `json:"error,omitempty"`
+	Score *float64 `json:"score,omitempty"`
+}
+
+// frequencies returns the k most frequent terms in counts, or all of them
+// if k is zero or less.
+func frequencies(counts map[string]int, k int) []record {
+	top := topK(counts, k)
+	recs := make([]record, len(top))
+	for i, f := range top {
+		recs[i] = record{Term: f.word, Count: f.count}
+	}
+	return recs
+}
+
+// rank sorts recs by score, highest first, breaking ties by count and then
+// by term, and returns the first k, or all of them if k is zero or less.
+func rank(recs []record, k int) []record {
+	sort.Slice(recs, func(i, j int) bool {
+		a, b := recs[i], recs[j]
+		if *a.Score != *b.Score {
+			return *a.Score > *b.Score
+		}
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Term < b.Term
+	})
+	if k > 0 && k < len(recs) {
+		recs = recs[:k]
+	}
+	return recs
+}
+
+// collocations scores the bigrams of t that occur at least min times by
+// their pointwise mutual information,
+//
+//	PMI(x y) = log₂ P(x y) / (P(x) P(y)),
+//
+// which is high when x and y appear together much more often than chance
+// would predict. Rare bigrams get unreliably high scores, hence min.
+func collocations(t *tally, min, k int) []record {
+	words, pairs := t.grams[0], t.grams[1]
+	n1, n2 := float64(t.total[0]), float64(t.total[1])
+	var recs []record
+	for g, c := range pairs {
+		if c < min {
+			continue
+		}
+		i := strings.IndexByte(g, ' ')
+		x, y := g[:i], g[i+1:]
+		pmi := math.Log2(float64(c) / n2 / (float64(words[x]) / n1 * float64(words[y]) / n1))
+		recs = append(recs, record{Term: g, Count: c, Score: &pmi})
+	}
+	return rank(recs, k)
+}
+
+// tfidf scores the words of each document by term frequency times inverse
+// document frequency,
+//
+//	tf-idf(w, d) = count(w, d) / len(d) × (ln((1+N) / (1+df(w))) + 1),
+//
+// where N is the number of documents and df(w) the number that contain w.
+// The smoothing keeps words found in every document from scoring zero. It
+// returns the k best words of each document, documents in the order given;
+// nil documents, which could not be read, are skipped.
+func tfidf(names []string, docs []*tally, k int) []record {
+	df := make(map[string]int)
+	n := 0
+	for _, d := range docs {
+		if d == nil {
+			continue
+		}
+		n++
+		for w := range d.grams[0] {
+			df[w]++
+		}
+	}
+
+	var out []record
+	for i, d := range docs {
+		if d == nil {
+			continue
+		}
+		var recs []record
+		for w, c := range d.grams[0] {
+			idf := math.Log(float64(1+n)/float64(1+df[w])) + 1
+			score := float64(c) / float64(d.total[0]) * idf
+			recs = append(recs, record{Doc: names[i], Term: w, Count: c, Score: &score})
+		}
+		out = append(out, rank(recs, k)...)
+	}
+	return out
+}
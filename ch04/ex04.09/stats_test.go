@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bytes"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func tallyOf(t *testing.T, maxN int, texts ...string) *tally {
+	t.Helper()
+	tl := newTally(maxN)
+	for _, s := range texts {
+		if err := tl.add(newTokenizer(nil), strings.NewReader(s)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return tl
+}
+
+func TestTallyNGrams(t *testing.T) {
+	tl := tallyOf(t, 3, "a b\nc a b", "c a")
+	expected := []map[string]int{
+		{"a": 3, "b": 2, "c": 2},
+		{"a b": 2, "b c": 1, "c a": 2},
+		{"a b c": 1, "b c a": 1, "c a b": 1},
+	}
+	if !reflect.DeepEqual(expected, tl.grams) {
+		t.Errorf("expected %v; actual %v", expected, tl.grams)
+	}
+	if want := []int{7, 5, 3}; !reflect.DeepEqual(want, tl.total) {
+		t.Errorf("totals: expected %v; actual %v", want, tl.total)
+	}
+}
+
+func TestCollocations(t *testing.T) {
+	text := strings.Repeat("new york is big and new ideas are big in new york ", 3)
+	recs := collocations(tallyOf(t, 2, text), 3, 0)
+
+	// There are 36 words and 35 bigrams. "new york" occurs 6 times, "new"
+	// 9 times and "york" 6 times.
+	want := math.Log2(6.0 / 35 / (9.0 / 36 * 6.0 / 36))
+	found := false
+	for i, r := range recs {
+		if r.Count < 3 {
+			t.Errorf("%q occurs %d times, below the minimum", r.Term, r.Count)
+		}
+		if i > 0 && *r.Score > *recs[i-1].Score {
+			t.Errorf("%q ranks below %q but scores higher", r.Term, recs[i-1].Term)
+		}
+		if r.Term == "new york" {
+			found = true
+			if math.Abs(*r.Score-want) > 1e-9 {
+				t.Errorf("PMI of %q: expected %g; actual %g", r.Term, want, *r.Score)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("expected \"new york\" among %+v", recs)
+	}
+	// "ideas are" pairs two words that occur only together.
+	if recs[0].Term != "ideas are" {
+		t.Errorf("expected \"ideas are\" first; actual %q", recs[0].Term)
+	}
+	if top := collocations(tallyOf(t, 2, text), 3, 2); len(top) != 2 {
+		t.Errorf("with k = 2: expected 2 records; actual %d", len(top))
+	}
+}
+
+func TestTFIDF(t *testing.T) {
+	names := []string{"one", "missing", "two"}
+	docs := []*tally{tallyOf(t, 1, "go go gopher"), nil, tallyOf(t, 1, "go rust rust")}
+	recs := tfidf(names, docs, 1)
+
+	// "gopher" is in one of two documents, so its idf is ln(3/2)+1; "go" is
+	// in both and gets 1.
+	gopher := 1.0 / 3 * (math.Log(1.5) + 1)
+	goScore := 2.0 / 3
+	rust := 2.0 / 3 * (math.Log(1.5) + 1)
+	if len(recs) != 2 {
+		t.Fatalf("expected one record for each readable document; actual %+v", recs)
+	}
+	if r := recs[0]; r.Doc != "one" || r.Term != "go" || math.Abs(*r.Score-goScore) > 1e-9 {
+		t.Errorf("document one: expected go with %g; actual %+v (gopher scores %g)", goScore, r, gopher)
+	}
+	if r := recs[1]; r.Doc != "two" || r.Term != "rust" || math.Abs(*r.Score-rust) > 1e-9 {
+		t.Errorf("document two: expected rust with %g; actual %+v", rust, r)
+	}
+}
+
+func TestWriters(t *testing.T) {
+	score := 1.5
+	recs := []record{
+		{Doc: "a.txt", Term: "go", Count: 2, Score: &score},
+		{Doc: "b.txt", Term: "say \"hi\"", Count: 1, Score: &score},
+	}
+	tests := map[string]struct {
+		write    func(w *bytes.Buffer) error
+		expected string
+	}{
+		"text words": {
+			write:    func(w *bytes.Buffer) error { return writeText(w, frequencies(map[string]int{"b": 1, "a": 1}, 0)) },
+			expected: "       1: a\n       1: b\n",
+		},
+		"text scores": {
+			write:    func(w *bytes.Buffer) error { return writeText(w, recs) },
+			expected: "==> a.txt <==\n    1.5000        2: go\n\n==> b.txt <==\n    1.5000        1: say \"hi\"\n",
+		},
+		"csv words": {
+			write:    func(w *bytes.Buffer) error { return writeCSV(w, frequencies(map[string]int{"a, b": 3}, 0)) },
+			expected: "term,count\n\"a, b\",3\n",
+		},
+		"csv scores": {
+			write:    func(w *bytes.Buffer) error { return writeCSV(w, recs) },
+			expected: "doc,term,count,score\na.txt,go,2,1.5\nb.txt,\"say \"\"hi\"\"\",1,1.5\n",
+		},
+		"json empty": {
+			write:    func(w *bytes.Buffer) error { return writeJSON(w, nil) },
+			expected: "[]\n",
+		},
+		"json words": {
+			write:    func(w *bytes.Buffer) error { return writeJSON(w, frequencies(map[string]int{"go": 2}, 0)) },
+			expected: "[\n  {\n    \"term\": \"go\",\n    \"count\": 2\n  }\n]\n",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var b bytes.Buffer
+			if err := tc.write(&b); err != nil {
+				t.Fatal(err)
+			}
+			if actual := b.String(); tc.expected != actual {
+				t.Errorf("expected %q; actual %q", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestCountFilesPerDoc(t *testing.T) {
+	dir := t.TempDir()
+	names := []string{filepath.Join(dir, "a"), filepath.Join(dir, "missing"), filepath.Join(dir, "b")}
+	os.WriteFile(names[0], []byte("x y"), 0o644)
+	os.WriteFile(names[2], []byte("y z z"), 0o644)
+
+	docs, errs := countFiles(names, countOptions{workers: 2, maxN: 2, perDoc: true})
+	if len(errs) != 1 {
+		t.Errorf("expected 1 error; actual %v", errs)
+	}
+	if docs[1] != nil {
+		t.Errorf("expected no tally for the missing file; actual %+v", docs[1])
+	}
+	if want := map[string]int{"x y": 1}; !reflect.DeepEqual(want, docs[0].grams[1]) {
+		t.Errorf("first file: expected %v; actual %v", want, docs[0].grams[1])
+	}
+	if want := map[string]int{"y z": 1, "z z": 1}; !reflect.DeepEqual(want, docs[2].grams[1]) {
+		t.Errorf("last file: expected %v; actual %v", want, docs[2].grams[1])
+	}
+}
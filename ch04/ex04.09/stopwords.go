@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"strings"
+
+	"golang.org/x/text/cases"
+)
+
+// english is a short list of common English function words.
+var english = strings.Fields(`
+	a about above after again against all am an and any are as at be because
+	been before being below between both but by can could did do does doing
+	down during each few for from further had has have having he her here hers
+	herself him himself his how i if in into is it its itself just me more
+	most my myself no nor not now of off on once only or other our ours
+	ourselves out over own same she should so some such than that the their
+	theirs them themselves then there these they this those through to too
+	under until up very was we were what when where which while who whom why
+	will with would you your yours yourself yourselves
+`)
+
+// loadStopWords returns the stop words named by spec: none if spec is empty,
+// the built-in list if it is "english", and otherwise the words in the file
+// spec, one per line, where blank lines and lines starting with '#' are
+// ignored. The words are case folded to match the tokenizer's output.
+func loadStopWords(spec string) (map[string]bool, error) {
+	stop := make(map[string]bool)
+	fold := cases.Fold()
+	switch spec {
+	case "":
+		return stop, nil
+	case "english":
+		for _, w := range english {
+			stop[fold.String(w)] = true
+		}
+		return stop, nil
+	}
+
+	f, err := os.Open(spec)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	input := bufio.NewScanner(f)
+	for input.Scan() {
+		w := strings.TrimSpace(input.Text())
+		if w == "" || strings.HasPrefix(w, "#") {
+			continue
+		}
+		stop[fold.String(w)] = true
+	}
+	return stop, input.Err()
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"container/heap"
+	"sort"
+)
+
+type wordFreq struct {
+	word  string
+	count int
+}
+
+// before reports whether a ranks ahead of b: higher counts first, and equal
+// counts in lexical order, so that output never depends on map order.
+func before(a, b wordFreq) bool {
+	if a.count != b.count {
+		return a.count > b.count
+	}
+	return a.word < b.word
+}
+
+// freqHeap is a min-heap by rank: its root is the lowest-ranked entry.
+type freqHeap []wordFreq
+
+func (h freqHeap) Len() int            { return len(h) }
+func (h freqHeap) Less(i, j int) bool  { return before(h[j], h[i]) }
+func (h freqHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *freqHeap) Push(x interface{}) { *h = append(*h, x.(wordFreq)) }
+func (h *freqHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// topK returns the k highest-ranked entries of counts in rank order, or all
+// of them if k is zero or less. It keeps at most k entries in a heap, so it
+// needs no more memory than the output.
+func topK(counts map[string]int, k int) []wordFreq {
+	if k <= 0 || k > len(counts) {
+		all := make([]wordFreq, 0, len(counts))
+		for w, c := range counts {
+			all = append(all, wordFreq{w, c})
+		}
+		sort.Slice(all, func(i, j int) bool { return before(all[i], all[j]) })
+		return all
+	}
+
+	h := make(freqHeap, 0, k)
+	for w, c := range counts {
+		f := wordFreq{w, c}
+		if len(h) < k {
+			heap.Push(&h, f)
+		} else if before(f, h[0]) {
+			h[0] = f
+			heap.Fix(&h, 0)
+		}
+	}
+	top := make([]wordFreq, len(h))
+	for i := len(top) - 1; i >= 0; i-- {
+		top[i] = heap.Pop(&h).(wordFreq)
+	}
+	return top
+}
@@ -1,44 +1,220 @@
+// Wordfreq reports the frequency of each word in its input.
+//
+// Usage:
+//
+//	wordfreq [-mode words|ngrams|pmi|tfidf] [-n n] [-min n] [-k n]
+//	         [-stop english|file] [-j n] [-format text|csv|json] [file ...]
+//	wordfreq -mode approx [-eps e] [-delta d] [-save file] [-merge]
+//	         [flags] [file ...]
+//
+// Wordfreq reads the named files, or the standard input if there are none,
+// and splits them into words by the Unicode word-boundary rules, folding
+// case so that "Go," and "go" count as the same word. Files are counted in
+// parallel. Results are printed highest first, with ties in lexical order.
+//
+// The -mode flag selects what is counted:
+//
+//	words   how often each word occurs
+//	ngrams  how often each sequence of -n words occurs
+//	pmi     bigrams seen at least -min times, scored by pointwise mutual
+//	        information, to find collocations such as "new york"
+//	tfidf   for each file, its words scored by tf-idf against all the files
+//	approx  estimated counts of the most frequent words, in fixed memory
+//
+// With -k, only the top n results are printed, or the top n for each file in
+// tfidf mode.
+//
+// Approx mode is for inputs whose vocabulary will not fit in memory. It
+// sketches the words with a Count-Min Sketch and a Space-Saving summary,
+// whose size depends only on -eps and -delta. Each word is printed with an
+// upper bound on its count and, after "±", the width of the range its true
+// count lies in; no count is off by more than eps times the number of words.
+// With -save, the sketch is also written to a file, and with -merge, the
+// arguments are such files, from shards of a corpus, to be combined.
 package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
+	"io"
 	"os"
-	"sort"
+	"runtime"
 )
 
-type wordFreq struct {
-	word  string
-	count int
-}
-
-type freqList []wordFreq
+var (
+	mode    = flag.String("mode", "words", "what to count: words, ngrams, pmi, tfidf or approx")
+	gramLen = flag.Int("n", 2, "in ngrams mode, count sequences of `n` words")
+	minPair = flag.Int("min", 3, "in pmi mode, ignore bigrams seen fewer than `n` times")
+	top     = flag.Int("k", 0, "print only the `n` highest results (0 means all)")
+	stopSet = flag.String("stop", "", "ignore the words in `list`: \"english\" or a file with one word per line")
+	workers = flag.Int("j", runtime.NumCPU(), "count up to `n` files at once")
+	format  = flag.String("format", "text", "output format: text, csv or json")
+	eps     = flag.Float64("eps", 1e-4, "in approx mode, the greatest error as a fraction of the number of words")
+	delta   = flag.Float64("delta", 1e-3, "in approx mode, the chance that a Count-Min estimate exceeds the error bound")
+	save    = flag.String("save", "", "in approx mode, also write the sketch to `file`")
+	merge   = flag.Bool("merge", false, "in approx mode, merge sketches saved with -save instead of reading text")
+)
 
 func main() {
-	scanner := bufio.NewScanner(os.Stdin)
-	scanner.Split(bufio.ScanWords)
-	freqMap := make(map[string]int)
+	flag.Parse()
+	var write func(io.Writer, []record) error
+	switch *format {
+	case "text":
+		write = writeText
+	case "csv":
+		write = writeCSV
+	case "json":
+		write = writeJSON
+	default:
+		fmt.Fprintf(os.Stderr, "wordfreq: unknown format %q\n", *format)
+		os.Exit(2)
+	}
+	opts := countOptions{workers: *workers, maxN: 1}
+	switch *mode {
+	case "words":
+	case "ngrams":
+		if *gramLen < 1 {
+			fmt.Fprintf(os.Stderr, "wordfreq: -n must be at least 1\n")
+			os.Exit(2)
+		}
+		opts.maxN = *gramLen
+	case "pmi":
+		opts.maxN = 2
+	case "tfidf":
+		opts.perDoc = true
+	case "approx":
+		if !(*eps > 0 && *eps < 1) || !(*delta > 0 && *delta < 1) {
+			fmt.Fprintf(os.Stderr, "wordfreq: -eps and -delta must be between 0 and 1\n")
+			os.Exit(2)
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "wordfreq: unknown mode %q\n", *mode)
+		os.Exit(2)
+	}
 
-	for scanner.Scan() {
-		freqMap[scanner.Text()]++
+	stop, err := loadStopWords(*stopSet)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "wordfreq:", err)
+		os.Exit(1)
 	}
-	if err := scanner.Err(); err != nil {
+	opts.stop = stop
+
+	names := flag.Args()
+	if len(names) == 0 {
+		names = []string{"-"}
+	}
+	// Files are read concurrently, so two "-" would read the standard
+	// input at once and split its words between them.
+	stdin := 0
+	for _, name := range names {
+		if name == "-" {
+			stdin++
+		}
+	}
+	if stdin > 1 {
+		fmt.Fprintf(os.Stderr, "wordfreq: \"-\" may be given only once\n")
+		os.Exit(2)
+	}
+	var recs []record
+	var errs []error
+	if *mode == "approx" {
+		recs, errs = approximate(names, opts)
+	} else {
+		recs, errs = exact(names, opts)
+	}
+	for _, err := range errs {
 		fmt.Fprintln(os.Stderr, "wordfreq:", err)
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	err = write(out, recs)
+	if flushErr := out.Flush(); err == nil {
+		err = flushErr
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "wordfreq:", err)
+		os.Exit(1)
+	}
+	if len(errs) > 0 {
 		os.Exit(1)
 	}
+}
+
+// exact counts names as the words, ngrams, pmi and tfidf modes require.
+func exact(names []string, opts countOptions) ([]record, []error) {
+	docs, errs := countFiles(names, opts)
+	var recs []record
+	switch *mode {
+	case "words", "ngrams":
+		recs = frequencies(docs[0].grams[opts.maxN-1], *top)
+	case "pmi":
+		recs = collocations(docs[0], *minPair, *top)
+	case "tfidf":
+		recs = tfidf(names, docs, *top)
+	}
+	return recs, errs
+}
+
+// approximate sketches names, or merges the sketches they hold if -merge is
+// set, and saves the result if -save is set.
+func approximate(names []string, opts countOptions) ([]record, []error) {
+	var sk *sketch
+	var errs []error
+	if *merge {
+		sk, errs = mergeSketches(names)
+	} else {
+		sk, errs = sketchFiles(names, opts.workers, opts.stop, *eps, *delta)
+	}
+	if sk == nil {
+		return nil, errs
+	}
+	if *save != "" {
+		if err := saveSketch(*save, sk); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return sk.top(*top), errs
+}
 
-	frequencies := make(freqList, len(freqMap))
-	i := 0
-	for w, c := range freqMap {
-		frequencies[i] = wordFreq{word: w, count: c}
-		i++
+// mergeSketches loads and merges the sketches in the named files, where "-"
+// is the standard input. It returns nil if none could be loaded.
+func mergeSketches(names []string) (*sketch, []error) {
+	var total *sketch
+	var errs []error
+	for _, name := range names {
+		f := os.Stdin
+		if name != "-" {
+			var err error
+			if f, err = os.Open(name); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+		}
+		sk, err := loadSketch(f)
+		if f != os.Stdin {
+			f.Close()
+		}
+		if err == nil && total != nil {
+			err = total.merge(sk)
+		} else if err == nil {
+			total = sk
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", name, err))
+		}
 	}
-	// sort.Sort(sort.Reverse(frequencies))
-	sort.Slice(frequencies, func(i, j int) bool {
-		return frequencies[i].count > frequencies[j].count
-	})
+	return total, errs
+}
 
-	for _, f := range frequencies {
-		fmt.Printf("%8d: %s\n", f.count, f.word)
+func saveSketch(name string, sk *sketch) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := sk.save(f); err != nil {
+		f.Close()
+		return fmt.Errorf("%s: %v", name, err)
 	}
+	return f.Close()
 }
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func tokens(t *testing.T, s string, stop map[string]bool) []string {
+	t.Helper()
+	var ws []string
+	if err := newTokenizer(stop).words(strings.NewReader(s), func(w string) { ws = append(ws, w) }); err != nil {
+		t.Fatal(err)
+	}
+	return ws
+}
+
+func TestTokenizer(t *testing.T) {
+	tests := map[string]struct {
+		s        string
+		expected []string
+	}{
+		"punctuation and case": {s: `Go, go GO! "Go."`, expected: []string{"go", "go", "go", "go"}},
+		"contractions":         {s: "don't can't", expected: []string{"don't", "can't"}},
+		"numbers":              {s: "3.14 and 42", expected: []string{"3.14", "and", "42"}},
+		"full case folding":    {s: "Straße STRASSE", expected: []string{"strasse", "strasse"}},
+		"across lines":         {s: "one\ntwo\r\nthree", expected: []string{"one", "two", "three"}},
+		"no words":             {s: " -- !? ", expected: nil},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual := tokens(t, tc.s, nil)
+			if !reflect.DeepEqual(tc.expected, actual) {
+				t.Errorf("expected %q; actual %q", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestStopWords(t *testing.T) {
+	stop, err := loadStopWords("english")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"cat", "mat"}
+	if actual := tokens(t, "The cat is on THE mat", stop); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %q; actual %q", expected, actual)
+	}
+
+	name := filepath.Join(t.TempDir(), "stop.txt")
+	if err := os.WriteFile(name, []byte("# pets\nCat\n\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if stop, err = loadStopWords(name); err != nil {
+		t.Fatal(err)
+	}
+	expected = []string{"the", "is", "on", "the", "mat"}
+	if actual := tokens(t, "The cat is on THE mat", stop); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %q; actual %q", expected, actual)
+	}
+}
+
+func TestTopK(t *testing.T) {
+	counts := map[string]int{"d": 1, "b": 3, "a": 3, "c": 2, "e": 1, "f": 3}
+	all := []wordFreq{{"a", 3}, {"b", 3}, {"f", 3}, {"c", 2}, {"d", 1}, {"e", 1}}
+
+	for k := 0; k <= len(all)+1; k++ {
+		expected := all
+		if k > 0 && k < len(all) {
+			expected = all[:k]
+		}
+		if actual := topK(counts, k); !reflect.DeepEqual(expected, actual) {
+			t.Errorf("topK(counts, %d): expected %v; actual %v", k, expected, actual)
+		}
+	}
+}
+
+func TestCountFiles(t *testing.T) {
+	dir := t.TempDir()
+	var names []string
+	for i := 0; i < 5; i++ {
+		name := filepath.Join(dir, strconv.Itoa(i)+".txt")
+		text := strings.Repeat("alpha Beta ", i+1) + "gamma"
+		if err := os.WriteFile(name, []byte(text), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, name)
+	}
+	expected := map[string]int{"alpha": 15, "beta": 15, "gamma": 5}
+
+	for _, workers := range []int{1, 2, 8} {
+		docs, errs := countFiles(names, countOptions{workers: workers})
+		if len(errs) > 0 {
+			t.Fatalf("with %d workers: %v", workers, errs)
+		}
+		if counts := docs[0].grams[0]; !reflect.DeepEqual(expected, counts) {
+			t.Errorf("with %d workers: expected %v; actual %v", workers, expected, counts)
+		}
+	}
+
+	docs, errs := countFiles(append(names, filepath.Join(dir, "missing")), countOptions{workers: 3})
+	if len(errs) != 1 {
+		t.Errorf("with a missing file: expected 1 error; actual %v", errs)
+	}
+	if counts := docs[0].grams[0]; !reflect.DeepEqual(expected, counts) {
+		t.Errorf("with a missing file: expected %v; actual %v", expected, counts)
+	}
+}
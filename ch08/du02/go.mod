@@ -1,3 +1,7 @@
 module git.sr.ht/~telemachus/gopl/ch08/du02
 
 go 1.17
+
+require gopl/ch03 v0.0.0
+
+replace gopl/ch03 => ../../ch03/ex03.11
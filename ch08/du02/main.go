@@ -16,10 +16,13 @@ import (
 	"os"
 	"path/filepath"
 	"time"
+
+	"gopl/ch03/units"
 )
 
 //!+
 var verbose = flag.Bool("v", false, "show verbose progress messages")
+var iec = flag.Bool("iec", false, "show sizes in powers of 1024 (KiB, MiB...)")
 
 func main() {
 	// ...start background goroutine...
@@ -67,7 +70,11 @@ loop:
 //!-
 
 func printDiskUsage(nfiles, nbytes int64) {
-	fmt.Printf("%d files  %.1f GB\n", nfiles, float64(nbytes)/1e9)
+	system := units.SI
+	if *iec {
+		system = units.IEC
+	}
+	fmt.Printf("%d files  %s\n", nfiles, units.FormatBytes(nbytes, system))
 }
 
 // walkDir recursively walks the file tree rooted at dir
@@ -1,3 +1,13 @@
 module git.sr.ht/~telemachus/gopl/ch08/du04
 
 go 1.17
+
+require (
+	gopl/ch03 v0.0.0
+	gopl/ch08/walk v0.0.0
+)
+
+replace (
+	gopl/ch03 => ../../ch03/ex03.11
+	gopl/ch08/walk => ../walk
+)
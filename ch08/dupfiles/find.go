@@ -0,0 +1,184 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"gopl/ch08/walk"
+)
+
+// partialSize is how much of each file is hashed to split a group of
+// same-sized files before any file is read in full.
+const partialSize = 4096
+
+// A file is a regular file found by the walk.
+type file = walk.File
+
+// hashers is how many files are hashed at once.
+const hashers = 20
+
+// A group is a set of files with identical contents.
+type group struct {
+	Size  int64    `json:"size"`
+	Hash  string   `json:"sha256"`
+	Files []string `json:"files"`
+}
+
+// findDups returns the groups of files with identical contents among files,
+// ignoring files smaller than minSize. Files are compared in three rounds,
+// each reading more than the last but only of the files still in the
+// running: by size, by a hash of their first partialSize bytes, and by
+// SHA-256 of their whole contents. A file reached through a symbolic link by more than one
+// path counts once, under the first of them. Unless hardlinks is set, so do
+// several hard links to the same file. The groups are sorted by size, largest
+// first, and then by their first path; the paths in each group are sorted
+// too. Files that cannot be read are left out, and their errors returned.
+func findDups(files []file, minSize int64, hardlinks bool) ([]group, []error) {
+	bySize := make(map[int64][]file)
+	for _, f := range files {
+		if f.Info.Mode().IsRegular() && f.Info.Size() >= minSize {
+			bySize[f.Info.Size()] = append(bySize[f.Info.Size()], f)
+		}
+	}
+
+	var candidates [][]file
+	for _, fs := range bySize {
+		sort.Slice(fs, func(i, j int) bool { return fs[i].Path < fs[j].Path })
+		fs = distinct(fs, hardlinks)
+		if len(fs) > 1 {
+			candidates = append(candidates, fs)
+		}
+	}
+
+	var groups []group
+	var errs []error
+	split := func(fs []file, n int64) map[string][]file {
+		byHash, splitErrs := splitByHash(fs, n)
+		errs = append(errs, splitErrs...)
+		return byHash
+	}
+	for _, fs := range candidates {
+		size := fs[0].Info.Size()
+		partial := split(fs, partialSize)
+		for h, same := range partial {
+			if len(same) < 2 {
+				continue
+			}
+			if size <= partialSize {
+				groups = append(groups, newGroup(size, h, same))
+				continue
+			}
+			for h, same := range split(same, -1) {
+				if len(same) > 1 {
+					groups = append(groups, newGroup(size, h, same))
+				}
+			}
+		}
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Size != groups[j].Size {
+			return groups[i].Size > groups[j].Size
+		}
+		return groups[i].Files[0] < groups[j].Files[0]
+	})
+	return groups, errs
+}
+
+func newGroup(size int64, hash string, fs []file) group {
+	g := group{Size: size, Hash: hash}
+	for _, f := range fs {
+		g.Files = append(g.Files, f.Path)
+	}
+	sort.Strings(g.Files)
+	return g
+}
+
+// distinct drops files that are the same file as one earlier in fs, reached
+// through a symbolic link, or, unless hardlinks is set, by a hard link.
+func distinct(fs []file, hardlinks bool) []file {
+	var out []file
+outer:
+	for _, f := range fs {
+		for _, g := range out {
+			if os.SameFile(f.Info, g.Info) && (!hardlinks || samePath(f.Path, g.Path)) {
+				continue outer
+			}
+		}
+		out = append(out, f)
+	}
+	return out
+}
+
+// samePath reports whether a and b name the same directory entry once
+// symbolic links are resolved. Two hard links to a file do not.
+func samePath(a, b string) bool {
+	return realPath(a) == realPath(b)
+}
+
+func realPath(path string) string {
+	if p, err := filepath.EvalSymlinks(path); err == nil {
+		path = p
+	}
+	if p, err := filepath.Abs(path); err == nil {
+		path = p
+	}
+	return path
+}
+
+// splitByHash groups fs by the SHA-256 of their first n bytes, or of their
+// whole contents if n is negative. Files are hashed concurrently. Files that
+// cannot be read are left out, and their errors returned.
+func splitByHash(fs []file, n int64) (map[string][]file, []error) {
+	hashes := make([]string, len(fs))
+	errs := make([]error, len(fs))
+	sema := make(chan struct{}, hashers) // concurrency-limiting counting semaphore
+	var wg sync.WaitGroup
+	for i, f := range fs {
+		wg.Add(1)
+		go func(i int, path string) {
+			defer wg.Done()
+			sema <- struct{}{}        // acquire token
+			defer func() { <-sema }() // release token
+			hashes[i], errs[i] = hashFile(path, n)
+		}(i, f.Path)
+	}
+	wg.Wait()
+
+	byHash := make(map[string][]file)
+	var failed []error
+	for i, h := range hashes {
+		if errs[i] != nil {
+			failed = append(failed, errs[i])
+			continue
+		}
+		byHash[h] = append(byHash[h], fs[i])
+	}
+	return byHash, failed
+}
+
+// hashFile returns the hex SHA-256 of the first n bytes of the named file, or
+// of all of it if n is negative.
+func hashFile(path string, n int64) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if n >= 0 {
+		r = io.LimitReader(f, n)
+	}
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", fmt.Errorf("%s: %v", path, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
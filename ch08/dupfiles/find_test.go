@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"gopl/ch08/walk"
+)
+
+// tree writes the named files under a new temporary directory and returns
+// its path.
+func tree(t *testing.T, files map[string][]byte) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func collect(t *testing.T, roots []string, follow bool) []file {
+	t.Helper()
+	found := make(chan file)
+	go walk.Walk(roots, walk.Options{Follow: follow, Error: func(err error) { t.Error(err) }}, found)
+	var files []file
+	for f := range found {
+		files = append(files, f)
+	}
+	return files
+}
+
+// find runs findDups on the files under roots and fails t on any error.
+func find(t *testing.T, roots []string, follow bool, minSize int64, hardlinks bool) []group {
+	t.Helper()
+	groups, errs := findDups(collect(t, roots, follow), minSize, hardlinks)
+	for _, err := range errs {
+		t.Error(err)
+	}
+	return groups
+}
+
+// paths returns the files of each group relative to dir.
+func paths(t *testing.T, dir string, groups []group) [][]string {
+	t.Helper()
+	var out [][]string
+	for _, g := range groups {
+		var rel []string
+		for _, p := range g.Files {
+			r, err := filepath.Rel(dir, p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rel = append(rel, r)
+		}
+		out = append(out, rel)
+	}
+	return out
+}
+
+func TestFindDups(t *testing.T) {
+	big := bytes.Repeat([]byte("0123456789"), 1000)
+	sameStart := append(append([]byte(nil), big[:9999]...), 'x')
+	dir := tree(t, map[string][]byte{
+		"a/big":        big,
+		"b/c/big":      big,
+		"a/same-start": sameStart,
+		"small1":       []byte("hello\n"),
+		"a/small2":     []byte("hello\n"),
+		"other":        []byte("world\n"),
+		"empty1":       nil,
+		"empty2":       nil,
+	})
+
+	expected := [][]string{
+		{"a/big", "b/c/big"},
+		{"a/small2", "small1"},
+	}
+	for i := 0; i < 3; i++ {
+		actual := paths(t, dir, find(t, []string{dir}, false, 1, false))
+		if !reflect.DeepEqual(expected, actual) {
+			t.Fatalf("run %d: expected %v; actual %v", i, expected, actual)
+		}
+	}
+
+	actual := paths(t, dir, find(t, []string{dir}, false, 0, false))
+	if want := append(expected, []string{"empty1", "empty2"}); !reflect.DeepEqual(want, actual) {
+		t.Errorf("with min 0: expected %v; actual %v", want, actual)
+	}
+}
+
+func TestFindDupsLinks(t *testing.T) {
+	dir := tree(t, map[string][]byte{
+		"a/one": []byte("same\n"),
+		"b/two": []byte("same\n"),
+	})
+	if err := os.Link(filepath.Join(dir, "a/one"), filepath.Join(dir, "hard")); err != nil {
+		t.Skip("hard links not supported:", err)
+	}
+	if err := os.Symlink("../a", filepath.Join(dir, "b/alias")); err != nil {
+		t.Skip("symbolic links not supported:", err)
+	}
+	if err := os.Symlink("..", filepath.Join(dir, "a/loop")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("a/one", filepath.Join(dir, "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		follow, hardlinks bool
+		expected          [][]string
+	}{
+		"links count once": {
+			expected: [][]string{{"a/one", "b/two"}},
+		},
+		"hard links reported": {
+			hardlinks: true,
+			expected:  [][]string{{"a/one", "b/two", "hard"}},
+		},
+		"following links": {
+			follow:   true,
+			expected: [][]string{{"a/one", "b/two"}},
+		},
+		"following links, hard links reported": {
+			// b/alias/one and link are a/one under other names, not
+			// hard links to it.
+			follow:    true,
+			hardlinks: true,
+			expected:  [][]string{{"a/one", "b/two", "hard"}},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			groups := find(t, []string{dir}, tc.follow, 1, tc.hardlinks)
+			if actual := paths(t, dir, groups); !reflect.DeepEqual(tc.expected, actual) {
+				t.Errorf("expected %v; actual %v", tc.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+// The dupfiles command finds files with identical contents.
+//
+// Usage:
+//
+//	dupfiles [-json] [-min size] [-hardlinks] [-L] [-iec] [dir|file ...]
+//
+// Dupfiles walks the named directories, or the current directory, and
+// prints each group of files whose contents are the same: a line giving
+// their size, the start of their SHA-256 and their number, then one path per
+// line, then a blank line. Groups come largest first. Empty files are
+// ignored unless -min is 0. Symbolic links are skipped unless -L is given,
+// and a file reached through links by several paths is listed once. Hard
+// links to one file are listed once too, under their first path, unless
+// -hardlinks is given. Dupfiles exits with status 1 if any file or directory
+// could not be read.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"gopl/ch03/units"
+	"gopl/ch08/walk"
+)
+
+var (
+	asJSON    = flag.Bool("json", false, "print the groups as JSON")
+	hardlinks = flag.Bool("hardlinks", false, "report hard links to the same file as duplicates")
+	follow    = flag.Bool("L", false, "follow symbolic links")
+	iec       = flag.Bool("iec", false, "show sizes in powers of 1024 (KiB, MiB...)")
+	minSize   = units.Size(1)
+)
+
+func init() {
+	flag.Var(&minSize, "min", "ignore files smaller than `size`, such as 4KiB")
+}
+
+func main() {
+	flag.Parse()
+	roots := flag.Args()
+	if len(roots) == 0 {
+		roots = []string{"."}
+	}
+
+	status := 0
+	found := make(chan file)
+	go walk.Walk(roots, walk.Options{Follow: *follow, Error: func(err error) {
+		fmt.Fprintf(os.Stderr, "dupfiles: %v\n", err)
+		status = 1
+	}}, found)
+	var files []file
+	for f := range found {
+		files = append(files, f)
+	}
+	groups, errs := findDups(files, int64(minSize), *hardlinks)
+	for _, err := range errs {
+		fmt.Fprintf(os.Stderr, "dupfiles: %v\n", err)
+		status = 1
+	}
+
+	if *asJSON {
+		if groups == nil {
+			groups = []group{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(groups); err != nil {
+			fmt.Fprintf(os.Stderr, "dupfiles: %v\n", err)
+			os.Exit(1)
+		}
+		os.Exit(status)
+	}
+
+	system := units.SI
+	if *iec {
+		system = units.IEC
+	}
+	for _, g := range groups {
+		fmt.Printf("%s  %s  (%d files)\n", units.FormatBytes(g.Size, system), g.Hash[:12], len(g.Files))
+		for _, path := range g.Files {
+			fmt.Println(path)
+		}
+		fmt.Println()
+	}
+	os.Exit(status)
+}
@@ -0,0 +1,3 @@
+module gopl/ch08/walk
+
+go 1.17
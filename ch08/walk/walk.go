@@ -0,0 +1,158 @@
+// Package walk walks file trees concurrently, as the du commands of chapter 8
+// do: a goroutine for each directory, with a counting semaphore to limit how
+// many directories are read at once.
+package walk
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// A File is a file found by Walk.
+type File struct {
+	Path string
+	Info os.FileInfo
+}
+
+// Options control a walk.
+type Options struct {
+	// Follow makes Walk follow symbolic links. A link to a directory that
+	// is already being walked, further up the same path, is skipped.
+	Follow bool
+
+	// Done, if not nil, cancels the walk when it is closed.
+	Done <-chan struct{}
+
+	// Error, if not nil, is called with each error met, such as a
+	// directory that cannot be read. Calls are never concurrent.
+	Error func(error)
+}
+
+// concurrency is how many directories a walk reads at once.
+const concurrency = 20
+
+type walker struct {
+	opts  Options
+	files chan<- File
+	n     sync.WaitGroup
+	sema  chan struct{} // concurrency-limiting counting semaphore
+	mu    sync.Mutex    // serializes calls to opts.Error
+}
+
+// Walk walks the file trees rooted at roots and sends each file that is not
+// a directory on files, then closes files. A root that is not a directory is
+// sent as it is. Without opts.Follow, symbolic links are sent as links.
+func Walk(roots []string, opts Options, files chan<- File) {
+	w := &walker{opts: opts, files: files, sema: make(chan struct{}, concurrency)}
+	for _, root := range roots {
+		info, err := os.Stat(root)
+		if err != nil {
+			w.error(err)
+			continue
+		}
+		if !info.IsDir() {
+			files <- File{root, info}
+			continue
+		}
+		w.n.Add(1)
+		go w.walkDir(root, w.descend(nil, root))
+	}
+	w.n.Wait()
+	close(files)
+}
+
+func (w *walker) error(err error) {
+	if w.opts.Error == nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.opts.Error(err)
+}
+
+func (w *walker) cancelled() bool {
+	select {
+	case <-w.opts.Done:
+		return true
+	default:
+		return false
+	}
+}
+
+// descend returns the real paths of the directories from a root down to dir,
+// given those down to its parent, or nil if dir is one of them already. Only
+// when following links can a walk come back to a directory it is inside, and
+// only then are the paths kept.
+func (w *walker) descend(ancestors []string, dir string) []string {
+	if !w.opts.Follow {
+		return ancestors
+	}
+	real, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		real = dir
+	}
+	if abs, err := filepath.Abs(real); err == nil {
+		real = abs
+	}
+	for _, a := range ancestors {
+		if a == real {
+			return nil
+		}
+	}
+	return append(ancestors[:len(ancestors):len(ancestors)], real)
+}
+
+// walkDir recursively walks the file tree rooted at dir
+// and sends each file found on w.files.
+func (w *walker) walkDir(dir string, ancestors []string) {
+	defer w.n.Done()
+	if w.cancelled() {
+		return
+	}
+	for _, entry := range w.dirents(dir) {
+		path := filepath.Join(dir, entry.Name())
+		if w.opts.Follow && entry.Mode()&os.ModeSymlink != 0 {
+			target, err := os.Stat(path)
+			if err != nil {
+				w.error(err)
+				continue
+			}
+			entry = target
+		}
+		if !entry.IsDir() {
+			w.files <- File{path, entry}
+			continue
+		}
+		sub := w.descend(ancestors, path)
+		if w.opts.Follow && sub == nil {
+			continue // a link back up the tree
+		}
+		w.n.Add(1)
+		go w.walkDir(path, sub)
+	}
+}
+
+// dirents returns the entries of directory dir.
+func (w *walker) dirents(dir string) []os.FileInfo {
+	select {
+	case w.sema <- struct{}{}: // acquire token
+	case <-w.opts.Done:
+		return nil // cancelled
+	}
+	defer func() { <-w.sema }() // release token
+
+	f, err := os.Open(dir)
+	if err != nil {
+		w.error(err)
+		return nil
+	}
+	defer f.Close()
+
+	entries, err := f.Readdir(0) // 0 => no limit; read all entries
+	if err != nil {
+		w.error(err)
+		// Don't return: Readdir may return partial results.
+	}
+	return entries
+}
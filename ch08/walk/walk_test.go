@@ -0,0 +1,125 @@
+package walk_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"gopl/ch08/walk"
+)
+
+// tree makes the named files, each holding its own name, under a new
+// temporary directory and returns its path.
+func tree(t *testing.T, names ...string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// collect walks roots and returns the paths found relative to dir, sorted,
+// and the errors reported.
+func collect(t *testing.T, dir string, roots []string, opts walk.Options) ([]string, []error) {
+	t.Helper()
+	var errs []error
+	opts.Error = func(err error) { errs = append(errs, err) }
+	files := make(chan walk.File)
+	go walk.Walk(roots, opts, files)
+	var paths []string
+	for f := range files {
+		rel, err := filepath.Rel(dir, f.Path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, filepath.ToSlash(rel))
+	}
+	sort.Strings(paths)
+	return paths, errs
+}
+
+func TestWalk(t *testing.T) {
+	dir := tree(t, "a/one", "a/b/two", "a/b/c/three", "four")
+
+	tests := map[string]struct {
+		roots    []string
+		expected []string
+		errors   int
+	}{
+		"whole tree": {
+			roots:    []string{dir},
+			expected: []string{"a/b/c/three", "a/b/two", "a/one", "four"},
+		},
+		"subtree and file": {
+			roots:    []string{filepath.Join(dir, "a/b"), filepath.Join(dir, "four")},
+			expected: []string{"a/b/c/three", "a/b/two", "four"},
+		},
+		"missing root": {
+			roots:    []string{filepath.Join(dir, "missing"), filepath.Join(dir, "a/b/c")},
+			expected: []string{"a/b/c/three"},
+			errors:   1,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual, errs := collect(t, dir, tc.roots, walk.Options{})
+			if !reflect.DeepEqual(tc.expected, actual) {
+				t.Errorf("expected %v; actual %v", tc.expected, actual)
+			}
+			if len(errs) != tc.errors {
+				t.Errorf("expected %d errors; actual %v", tc.errors, errs)
+			}
+		})
+	}
+}
+
+func TestWalkLinks(t *testing.T) {
+	dir := tree(t, "a/one", "b/two")
+	if err := os.Symlink("../a", filepath.Join(dir, "b/up")); err != nil {
+		t.Skip("symbolic links not supported:", err)
+	}
+	if err := os.Symlink("..", filepath.Join(dir, "a/loop")); err != nil {
+		t.Fatal(err)
+	}
+
+	actual, errs := collect(t, dir, []string{dir}, walk.Options{})
+	if expected := []string{"a/loop", "a/one", "b/two", "b/up"}; !reflect.DeepEqual(expected, actual) {
+		t.Errorf("not following: expected %v; actual %v", expected, actual)
+	}
+
+	// b/up leads into a, which is not above it, so a is walked twice; a/loop
+	// leads back to the root, and is skipped wherever it is met.
+	actual, errs = collect(t, dir, []string{dir}, walk.Options{Follow: true})
+	if expected := []string{"a/one", "b/two", "b/up/one"}; !reflect.DeepEqual(expected, actual) {
+		t.Errorf("following: expected %v; actual %v", expected, actual)
+	}
+	if len(errs) != 0 {
+		t.Errorf("following: unexpected errors %v", errs)
+	}
+}
+
+func TestWalkCancel(t *testing.T) {
+	dir := tree(t, "a/one", "b/two")
+	done := make(chan struct{})
+	close(done)
+
+	var errs []error
+	files := make(chan walk.File)
+	go walk.Walk([]string{dir}, walk.Options{Done: done, Error: func(err error) { errs = append(errs, err) }}, files)
+	for f := range files {
+		t.Errorf("cancelled walk found %s", f.Path)
+	}
+	if len(errs) != 0 {
+		t.Errorf("unexpected errors %v", errs)
+	}
+}
@@ -1,19 +1,23 @@
 // Package char provides utility functions for Unicode characters.
 package char
 
-import "unicode"
+import "unicode/utf8"
 
 // Count returns a map counting Unicode characters in a string and an integer
-// with the number of invalid characters in the string.
+// with the number of invalid UTF-8 sequences in the string. A U+FFFD that is
+// properly encoded in s is counted as a character, not as invalid.
 func Count(s string) (map[rune]int, int) {
 	counts := make(map[rune]int)
 	invalid := 0
-	for _, r := range s {
-		if r == unicode.ReplacementChar {
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
 			invalid++
-		} else {
-			counts[r]++
+			i += invalidLen(s[i:])
+			continue
 		}
+		counts[r]++
+		i += size
 	}
 	return counts, invalid
 }
@@ -7,7 +7,7 @@ import (
 
 func TestCountInvalid(t *testing.T) {
 	expected := 3
-	s := "a�a�a�"
+	s := "a\xffa\xe2\x82a\xf0\x9f"
 	_, actual := char.Count(s)
 
 	if expected != actual {
@@ -27,3 +27,14 @@ func TestCountValid(t *testing.T) {
 		}
 	}
 }
+
+func TestCountReplacementChar(t *testing.T) {
+	s := "a\uFFFDb"
+	counts, invalid := char.Count(s)
+	if invalid != 0 {
+		t.Errorf("input string: %q, invalid = %d", s, invalid)
+	}
+	if counts['\uFFFD'] != 1 {
+		t.Errorf("input string: %q, but result['\\uFFFD'] != 1", s)
+	}
+}
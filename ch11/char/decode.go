@@ -0,0 +1,140 @@
+package char
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"unicode/utf8"
+)
+
+// An InvalidError records a sequence of bytes that is not valid UTF-8. Each
+// InvalidError covers one maximal subpart of an ill-formed sequence, as
+// defined by the Unicode Standard: the longest prefix that could begin a
+// well-formed sequence, or a single byte if no such prefix exists. This is the
+// unit that conforming decoders replace with one U+FFFD.
+type InvalidError struct {
+	Offset int64 `json:"offset"` // byte offset of the sequence in the input
+	Len    int   `json:"len"`    // length of the sequence in bytes
+}
+
+func (e *InvalidError) Error() string {
+	return fmt.Sprintf("invalid UTF-8 at byte %d (%d bytes)", e.Offset, e.Len)
+}
+
+// A Decoder reads runes from UTF-8 text and keeps track of byte offsets, so
+// that it can tell a U+FFFD present in the text from one that stands for
+// invalid input.
+type Decoder struct {
+	in  *bufio.Reader
+	off int64
+}
+
+// NewDecoder returns a Decoder that reads from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{in: bufio.NewReader(r)}
+}
+
+// Offset returns the byte offset of the next rune to be read.
+func (d *Decoder) Offset() int64 {
+	return d.off
+}
+
+// ReadRune reads the next rune and returns it with its size in bytes. At an
+// invalid sequence, ReadRune returns utf8.RuneError, the length of the
+// sequence and an *InvalidError; reading may continue after such an error.
+// At the end of the input ReadRune returns io.EOF.
+func (d *Decoder) ReadRune() (rune, int, error) {
+	r, size, err := d.in.ReadRune()
+	if err != nil {
+		return r, size, err
+	}
+	if r != utf8.RuneError || size != 1 {
+		d.off += int64(size)
+		return r, size, nil
+	}
+
+	// bufio.Reader reports each invalid byte on its own. Back up and measure
+	// the whole subpart instead.
+	d.in.UnreadRune()
+	p, _ := d.in.Peek(utf8.UTFMax)
+	size = invalidLen(string(p))
+	d.in.Discard(size)
+	e := &InvalidError{Offset: d.off, Len: size}
+	d.off += int64(size)
+	return utf8.RuneError, size, e
+}
+
+// FindInvalid reads r to EOF and returns every invalid sequence in it.
+func FindInvalid(r io.Reader) ([]*InvalidError, error) {
+	var invalid []*InvalidError
+	d := NewDecoder(r)
+	for {
+		_, _, err := d.ReadRune()
+		if err == io.EOF {
+			return invalid, nil
+		}
+		if e, ok := err.(*InvalidError); ok {
+			invalid = append(invalid, e)
+			continue
+		}
+		if err != nil {
+			return invalid, err
+		}
+	}
+}
+
+// Repair copies r to w, replacing each invalid sequence with U+FFFD so that
+// the output is valid UTF-8. It returns the invalid sequences it replaced.
+func Repair(w io.Writer, r io.Reader) ([]*InvalidError, error) {
+	var invalid []*InvalidError
+	out := bufio.NewWriter(w)
+	d := NewDecoder(r)
+	for {
+		c, _, err := d.ReadRune()
+		if err == io.EOF {
+			return invalid, out.Flush()
+		}
+		if e, ok := err.(*InvalidError); ok {
+			invalid = append(invalid, e)
+		} else if err != nil {
+			return invalid, err
+		}
+		if _, err := out.WriteRune(c); err != nil {
+			return invalid, err
+		}
+	}
+}
+
+// invalidLen returns the length of the maximal subpart at the start of s,
+// which must not begin with a valid UTF-8 sequence.
+func invalidLen(s string) int {
+	var n int
+	lo, hi := byte(0x80), byte(0xBF)
+	switch b := s[0]; {
+	case 0xC2 <= b && b <= 0xDF:
+		n = 2
+	case b == 0xE0:
+		n, lo = 3, 0xA0
+	case b == 0xED:
+		n, hi = 3, 0x9F
+	case 0xE1 <= b && b <= 0xEF:
+		n = 3
+	case b == 0xF0:
+		n, lo = 4, 0x90
+	case 0xF1 <= b && b <= 0xF3:
+		n = 4
+	case b == 0xF4:
+		n, hi = 4, 0x8F
+	default:
+		return 1
+	}
+
+	i := 1
+	for ; i < n && i < len(s); i++ {
+		if s[i] < lo || hi < s[i] {
+			break
+		}
+		lo, hi = 0x80, 0xBF
+	}
+	return i
+}
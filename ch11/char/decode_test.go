@@ -0,0 +1,84 @@
+package char_test
+
+import (
+	"bytes"
+	"errors"
+	"gopl/ch11/char"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestFindInvalid(t *testing.T) {
+	var tests = []struct {
+		input string
+		want  []char.InvalidError
+	}{
+		{"", nil},
+		{"plain ASCII", nil},
+		{"real \uFFFD replacement", nil},
+		{"a\xffb", []char.InvalidError{{1, 1}}},
+		{"\xff\xfe", []char.InvalidError{{0, 1}, {1, 1}}},
+		{"\xe2\x82", []char.InvalidError{{0, 2}}},                                 // truncated €
+		{"\xe2\x82x\xe2\x82\xac", []char.InvalidError{{0, 2}}},                    // then a real €
+		{"\xf0\x9f\x98", []char.InvalidError{{0, 3}}},                             // truncated 😀
+		{"\xc0\xaf", []char.InvalidError{{0, 1}, {1, 1}}},                         // overlong /
+		{"\xed\xa0\x80", []char.InvalidError{{0, 1}, {1, 1}, {2, 1}}},             // surrogate
+		{"\xf4\x90\x80\x80", []char.InvalidError{{0, 1}, {1, 1}, {2, 1}, {3, 1}}}, // > U+10FFFF
+		{"é\x80", []char.InvalidError{{2, 1}}},
+	}
+	for _, test := range tests {
+		got, err := char.FindInvalid(strings.NewReader(test.input))
+		if err != nil {
+			t.Errorf("FindInvalid(%q) failed: %v", test.input, err)
+			continue
+		}
+		if len(got) != len(test.want) {
+			t.Errorf("FindInvalid(%q) found %d sequences, want %d", test.input, len(got), len(test.want))
+			continue
+		}
+		for i := range got {
+			if *got[i] != test.want[i] {
+				t.Errorf("FindInvalid(%q)[%d] = %+v, want %+v", test.input, i, *got[i], test.want[i])
+			}
+		}
+	}
+}
+
+func TestRepair(t *testing.T) {
+	var tests = []struct {
+		input string
+		want  string
+	}{
+		{"", ""},
+		{"ok \uFFFD", "ok \uFFFD"},
+		{"a\xffb", "a\uFFFDb"},
+		{"\xe2\x82x", "\uFFFDx"},
+		{"\xc0\xaf", "\uFFFD\uFFFD"},
+	}
+	for _, test := range tests {
+		var out bytes.Buffer
+		if _, err := char.Repair(&out, strings.NewReader(test.input)); err != nil {
+			t.Errorf("Repair(%q) failed: %v", test.input, err)
+			continue
+		}
+		if got := out.String(); got != test.want {
+			t.Errorf("Repair(%q) = %q, want %q", test.input, got, test.want)
+		}
+		if !utf8.Valid(out.Bytes()) {
+			t.Errorf("Repair(%q) wrote invalid UTF-8 %q", test.input, out.String())
+		}
+	}
+}
+
+type failWriter struct{}
+
+func (failWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func TestRepairWriteError(t *testing.T) {
+	if _, err := char.Repair(failWriter{}, strings.NewReader("abc")); err == nil {
+		t.Error("Repair to a failing writer returned nil error")
+	}
+}
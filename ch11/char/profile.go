@@ -0,0 +1,177 @@
+package char
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+	"unicode"
+	"unicode/utf8"
+)
+
+// A Profile breaks text down by Unicode general category, script, UTF-8
+// encoded length and block. Create a Profile with NewProfile and build it up
+// incrementally with Add or ReadFrom. A Profile is not safe for concurrent
+// use, but profiles built in separate goroutines can be combined with Merge.
+type Profile struct {
+	Runes      int            `json:"runes"`      // number of valid runes
+	Invalid    int            `json:"invalid"`    // number of invalid UTF-8 sequences
+	Categories map[string]int `json:"categories"` // keyed by general category, e.g. "Lu"
+	Scripts    map[string]int `json:"scripts"`    // keyed by script, e.g. "Latin"
+	Lengths    map[int]int    `json:"lengths"`    // keyed by UTF-8 encoded length
+	Blocks     map[string]int `json:"blocks"`     // keyed by block, e.g. "Basic Latin"
+
+	// seen caches the classes of runes already added, since looking up
+	// categories and scripts means searching many tables.
+	seen map[rune]class
+}
+
+type class struct {
+	category, script, block string
+}
+
+// categories holds the names of the two-letter general categories in
+// unicode.Categories, sorted. LC (cased letter) is left out since it overlaps
+// Lu, Ll and Lt.
+var categories []string
+
+func init() {
+	for name := range unicode.Categories {
+		if len(name) == 2 && name != "LC" {
+			categories = append(categories, name)
+		}
+	}
+	sort.Strings(categories)
+}
+
+// NewProfile returns an empty Profile.
+func NewProfile() *Profile {
+	return &Profile{
+		Categories: make(map[string]int),
+		Scripts:    make(map[string]int),
+		Lengths:    make(map[int]int),
+		Blocks:     make(map[string]int),
+		seen:       make(map[rune]class),
+	}
+}
+
+// Add records one rune in p.
+func (p *Profile) Add(r rune) {
+	c, ok := p.seen[r]
+	if !ok {
+		c = classify(r)
+		p.seen[r] = c
+	}
+	p.Runes++
+	p.Categories[c.category]++
+	p.Scripts[c.script]++
+	p.Lengths[utf8.RuneLen(r)]++
+	p.Blocks[c.block]++
+}
+
+// ReadFrom adds every rune read from r to p until EOF. Invalid UTF-8 is
+// counted in p.Invalid, once for each InvalidError a Decoder would report. It
+// returns the number of bytes read and any error other than io.EOF.
+func (p *Profile) ReadFrom(r io.Reader) (int64, error) {
+	d := NewDecoder(r)
+	for {
+		c, _, err := d.ReadRune()
+		if err == io.EOF {
+			return d.Offset(), nil
+		}
+		if _, ok := err.(*InvalidError); ok {
+			p.Invalid++
+			continue
+		}
+		if err != nil {
+			return d.Offset(), err
+		}
+		p.Add(c)
+	}
+}
+
+// Merge adds the counts in q to p.
+func (p *Profile) Merge(q *Profile) {
+	p.Runes += q.Runes
+	p.Invalid += q.Invalid
+	for k, n := range q.Categories {
+		p.Categories[k] += n
+	}
+	for k, n := range q.Scripts {
+		p.Scripts[k] += n
+	}
+	for k, n := range q.Lengths {
+		p.Lengths[k] += n
+	}
+	for k, n := range q.Blocks {
+		p.Blocks[k] += n
+	}
+}
+
+// WriteTable writes p to w as a plain-text table, one section per breakdown.
+// Within a section, rows are sorted by descending count and then by name.
+func (p *Profile) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(tw, "runes\t%d\n", p.Runes)
+	fmt.Fprintf(tw, "invalid\t%d\n", p.Invalid)
+
+	lengths := make(map[string]int, len(p.Lengths))
+	for k, n := range p.Lengths {
+		lengths[fmt.Sprintf("%d bytes", k)] = n
+	}
+	sections := []struct {
+		title  string
+		counts map[string]int
+	}{
+		{"Categories", p.Categories},
+		{"Scripts", p.Scripts},
+		{"Lengths", lengths},
+		{"Blocks", p.Blocks},
+	}
+	for _, s := range sections {
+		fmt.Fprintf(tw, "\n%s:\n", s.title)
+		for _, k := range sortedKeys(s.counts) {
+			fmt.Fprintf(tw, "  %s\t%d\n", k, s.counts[k])
+		}
+	}
+	return tw.Flush()
+}
+
+// WriteJSON writes p to w as indented JSON.
+func (p *Profile) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(p)
+}
+
+func sortedKeys(counts map[string]int) []string {
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
+
+func classify(r rune) class {
+	c := class{category: "Cn", script: "Unknown", block: Block(r)}
+	for _, name := range categories {
+		if unicode.Is(unicode.Categories[name], r) {
+			c.category = name
+			break
+		}
+	}
+	for name, table := range unicode.Scripts {
+		if unicode.Is(table, r) {
+			c.script = name
+			break
+		}
+	}
+	return c
+}
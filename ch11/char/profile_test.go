@@ -0,0 +1,127 @@
+package char_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"gopl/ch11/char"
+	"strings"
+	"testing"
+)
+
+func TestProfileReadFrom(t *testing.T) {
+	s := "Go, Гоу! 囲碁\xff"
+	p := char.NewProfile()
+	n, err := p.ReadFrom(strings.NewReader(s))
+	if err != nil {
+		t.Fatalf("ReadFrom(%q) failed: %v", s, err)
+	}
+	if n != int64(len(s)) {
+		t.Errorf("ReadFrom(%q) read %d bytes, want %d", s, n, len(s))
+	}
+
+	var tests = []struct {
+		name   string
+		counts map[string]int
+		key    string
+		want   int
+	}{
+		{"categories", p.Categories, "Lu", 2},
+		{"categories", p.Categories, "Ll", 3},
+		{"categories", p.Categories, "Lo", 2},
+		{"categories", p.Categories, "Po", 2},
+		{"categories", p.Categories, "Zs", 2},
+		{"scripts", p.Scripts, "Latin", 2},
+		{"scripts", p.Scripts, "Cyrillic", 3},
+		{"scripts", p.Scripts, "Han", 2},
+		{"scripts", p.Scripts, "Common", 4},
+		{"blocks", p.Blocks, "Basic Latin", 6},
+		{"blocks", p.Blocks, "Cyrillic", 3},
+		{"blocks", p.Blocks, "CJK Unified Ideographs", 2},
+	}
+	for _, test := range tests {
+		if got := test.counts[test.key]; got != test.want {
+			t.Errorf("%s[%q] = %d, want %d", test.name, test.key, got, test.want)
+		}
+	}
+	if p.Runes != 11 || p.Invalid != 1 {
+		t.Errorf("Runes, Invalid = %d, %d, want 11, 1", p.Runes, p.Invalid)
+	}
+	if p.Lengths[1] != 6 || p.Lengths[2] != 3 || p.Lengths[3] != 2 {
+		t.Errorf("Lengths = %v, want map[1:6 2:3 3:2]", p.Lengths)
+	}
+}
+
+func TestProfileMerge(t *testing.T) {
+	whole := char.NewProfile()
+	whole.ReadFrom(strings.NewReader("abc Δ 😀"))
+
+	p := char.NewProfile()
+	p.ReadFrom(strings.NewReader("abc "))
+	q := char.NewProfile()
+	q.ReadFrom(strings.NewReader("Δ 😀"))
+	p.Merge(q)
+
+	var a, b bytes.Buffer
+	whole.WriteJSON(&a)
+	p.WriteJSON(&b)
+	if a.String() != b.String() {
+		t.Errorf("merged profile = %s, want %s", b.String(), a.String())
+	}
+}
+
+func TestProfileWriteJSON(t *testing.T) {
+	p := char.NewProfile()
+	p.ReadFrom(strings.NewReader("é"))
+
+	var buf bytes.Buffer
+	if err := p.WriteJSON(&buf); err != nil {
+		t.Fatalf("WriteJSON failed: %v", err)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("WriteJSON wrote invalid JSON %q: %v", buf.String(), err)
+	}
+	if got["runes"] != 1.0 {
+		t.Errorf("runes = %v, want 1", got["runes"])
+	}
+}
+
+func TestProfileWriteTable(t *testing.T) {
+	p := char.NewProfile()
+	p.ReadFrom(strings.NewReader("aab"))
+
+	var buf bytes.Buffer
+	if err := p.WriteTable(&buf); err != nil {
+		t.Fatalf("WriteTable failed: %v", err)
+	}
+	var rows []string
+	for _, line := range strings.Split(buf.String(), "\n") {
+		rows = append(rows, strings.Join(strings.Fields(line), " "))
+	}
+	got := strings.Join(rows, "\n")
+	for _, want := range []string{"Categories:", "Ll 3", "Latin 3", "1 bytes 3", "Basic Latin 3"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("WriteTable output missing %q:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestBlock(t *testing.T) {
+	var tests = []struct {
+		r    rune
+		want string
+	}{
+		{'a', "Basic Latin"},
+		{'é', "Latin-1 Supplement"},
+		{'Ж', "Cyrillic"},
+		{'碁', "CJK Unified Ideographs"},
+		{'😀', "Emoticons"},
+		{0x0870 - 1, "Syriac Supplement"},
+		{0x2FE0, "No_Block"},
+	}
+	for _, test := range tests {
+		if got := char.Block(test.r); got != test.want {
+			t.Errorf("Block(%U) = %q, want %q", test.r, got, test.want)
+		}
+	}
+}
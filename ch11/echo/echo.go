@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bytes"
 	"flag"
 	"fmt"
 	"io"
@@ -11,21 +12,152 @@ import (
 var (
 	n = flag.Bool("n", false, "omit trailing newline")
 	s = flag.String("s", " ", "separator character (default is single space)")
+
+	escapes bool
 )
 
+func init() {
+	flag.Var(escapeFlag{&escapes, true}, "e", "enable interpretation of backslash escapes")
+	flag.Var(escapeFlag{&escapes, false}, "E", "disable interpretation of backslash escapes (default)")
+}
+
+// escapeFlag lets -e and -E share one setting, so that the last one given
+// wins, as with GNU echo.
+type escapeFlag struct {
+	on    *bool
+	value bool
+}
+
+func (f escapeFlag) String() string {
+	return ""
+}
+
+func (f escapeFlag) Set(string) error {
+	*f.on = f.value
+	return nil
+}
+
+func (f escapeFlag) IsBoolFlag() bool {
+	return true
+}
+
 func main() {
 	flag.Parse()
-	if err := Echo(os.Stdout, !*n, *s, flag.Args()); err != nil {
+	if err := Echo(os.Stdout, !*n, escapes, *s, flag.Args()); err != nil {
 		fmt.Fprintf(os.Stderr, "echo: %v\n", err)
 		os.Exit(1)
 	}
 	os.Exit(0)
 }
 
-func Echo(out io.Writer, newline bool, sep string, args []string) error {
-	fmt.Fprint(out, strings.Join(args, sep))
+// Echo writes args to out, separated by sep and followed by a newline if
+// newline is true. If escapes is true, backslash escapes in args are
+// interpreted as GNU echo -e does. An escaped \c ends the output at once,
+// without a trailing newline. Echo returns any error from writing to out.
+func Echo(out io.Writer, newline, escapes bool, sep string, args []string) error {
+	if !escapes {
+		text := strings.Join(args, sep)
+		if newline {
+			text += "\n"
+		}
+		_, err := io.WriteString(out, text)
+		return err
+	}
+
+	var buf bytes.Buffer
+	for i, arg := range args {
+		if i > 0 {
+			buf.WriteString(sep)
+		}
+		if stop := unescape(&buf, arg); stop {
+			_, err := out.Write(buf.Bytes())
+			return err
+		}
+	}
 	if newline {
-		fmt.Fprintln(out)
+		buf.WriteByte('\n')
+	}
+	_, err := out.Write(buf.Bytes())
+	return err
+}
+
+// unescape writes s to buf with backslash escapes interpreted. It reports
+// whether s contained \c, in which case all output should stop.
+func unescape(buf *bytes.Buffer, s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			buf.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch c := s[i]; c {
+		case '\\':
+			buf.WriteByte('\\')
+		case 'a':
+			buf.WriteByte('\a')
+		case 'b':
+			buf.WriteByte('\b')
+		case 'c':
+			return true
+		case 'e':
+			buf.WriteByte(0x1b)
+		case 'f':
+			buf.WriteByte('\f')
+		case 'n':
+			buf.WriteByte('\n')
+		case 'r':
+			buf.WriteByte('\r')
+		case 't':
+			buf.WriteByte('\t')
+		case 'v':
+			buf.WriteByte('\v')
+		case '0', '1', '2', '3', '4', '5', '6', '7':
+			// \0 takes up to three more octal digits; \1 through \7 take
+			// up to two more. Values above 0377 wrap, as in GNU echo.
+			v, max := int(c-'0'), 2
+			if c == '0' {
+				max = 3
+			}
+			for j := 0; j < max && i+1 < len(s) && isOctal(s[i+1]); j++ {
+				i++
+				v = v*8 + int(s[i]-'0')
+			}
+			buf.WriteByte(byte(v))
+		case 'x':
+			v, digits := 0, 0
+			for digits < 2 && i+1 < len(s) && isHex(s[i+1]) {
+				i++
+				v = v*16 + hexValue(s[i])
+				digits++
+			}
+			if digits == 0 {
+				buf.WriteString(`\x`)
+			} else {
+				buf.WriteByte(byte(v))
+			}
+		default:
+			buf.WriteByte('\\')
+			buf.WriteByte(c)
+		}
+	}
+	return false
+}
+
+func isOctal(c byte) bool {
+	return '0' <= c && c <= '7'
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+func hexValue(c byte) int {
+	switch {
+	case c <= '9':
+		return int(c - '0')
+	case c <= 'F':
+		return int(c-'A') + 10
+	default:
+		return int(c-'a') + 10
 	}
-	return nil
 }
@@ -2,6 +2,7 @@ package main_test
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"testing"
 
@@ -11,23 +12,28 @@ import (
 func TestEcho(t *testing.T) {
 	var tests = []struct {
 		newline bool
+		escapes bool
 		sep     string
 		args    []string
 		want    string
 	}{
-		{true, "", []string{}, "\n"},
-		{false, "", []string{}, ""},
-		{true, "\t", []string{"one", "two", "three"}, "one\ttwo\tthree\n"},
-		{true, ",", []string{"a", "b", "c"}, "a,b,c\n"},
-		{false, ":", []string{"1", "2", "3"}, "1:2:3"},
+		{true, false, "", []string{}, "\n"},
+		{false, false, "", []string{}, ""},
+		{true, false, "\t", []string{"one", "two", "three"}, "one\ttwo\tthree\n"},
+		{true, false, ",", []string{"a", "b", "c"}, "a,b,c\n"},
+		{false, false, ":", []string{"1", "2", "3"}, "1:2:3"},
+		{true, false, " ", []string{`a\tb`}, "a\\tb\n"}, // -E is the default
+		{true, true, " ", []string{`a\tb`}, "a\tb\n"},
+		{true, true, " ", []string{`a\c`, "b"}, "a"},      // \c drops the rest and the newline
+		{true, true, `\t`, []string{"a", "b"}, "a\\tb\n"}, // the separator is literal
 	}
 
 	for _, test := range tests {
-		descr := fmt.Sprintf("echo(%v, %q, %q)",
-			test.newline, test.sep, test.args)
+		descr := fmt.Sprintf("echo(%v, %v, %q, %q)",
+			test.newline, test.escapes, test.sep, test.args)
 
 		out := new(bytes.Buffer) // captured output
-		if err := main.Echo(out, test.newline, test.sep, test.args); err != nil {
+		if err := main.Echo(out, test.newline, test.escapes, test.sep, test.args); err != nil {
 			t.Errorf("%s failed: %v", descr, err)
 			continue
 		}
@@ -37,3 +43,55 @@ func TestEcho(t *testing.T) {
 		}
 	}
 }
+
+// TestEchoEscapes checks escape handling against the output of GNU coreutils
+// 9.1 echo -e for the same argument.
+func TestEchoEscapes(t *testing.T) {
+	var tests = []struct {
+		arg  string
+		want string
+	}{
+		{`a\tb`, "a\tb\n"},
+		{`x\ny`, "x\ny\n"},
+		{`back\\slash`, "back\\slash\n"},
+		{`bell\a`, "bell\a\n"},
+		{`esc\e[0m`, "esc\x1b[0m\n"},
+		{`cr\rlf\f\v\b`, "cr\rlf\f\v\b\n"},
+		{`octal \0101\0102`, "octal AB\n"},
+		{`big \0777`, "big \xff\n"},
+		{`short \01x`, "short \x01x\n"},
+		{`nul \0`, "nul \x00\n"},
+		{`octal3 \101\1012`, "octal3 AA2\n"},
+		{`hex \x41\x4a\x4G`, "hex AJ\x04G\n"},
+		{`hexnone \xZ`, "hexnone \\xZ\n"},
+		{`trailing \`, "trailing \\\n"},
+		{`unknown \q`, "unknown \\q\n"},
+		{`stop\c after`, "stop"},
+	}
+
+	for _, test := range tests {
+		out := new(bytes.Buffer)
+		if err := main.Echo(out, true, true, " ", []string{test.arg}); err != nil {
+			t.Errorf("echo -e %q failed: %v", test.arg, err)
+			continue
+		}
+		if got := out.String(); got != test.want {
+			t.Errorf("echo -e %q = %q, want %q", test.arg, got, test.want)
+		}
+	}
+}
+
+type failWriter struct{}
+
+func (failWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func TestEchoWriteError(t *testing.T) {
+	for _, escapes := range []bool{false, true} {
+		err := main.Echo(failWriter{}, true, escapes, " ", []string{"a"})
+		if err == nil {
+			t.Errorf("echo(escapes=%v) to a failing writer returned nil error", escapes)
+		}
+	}
+}
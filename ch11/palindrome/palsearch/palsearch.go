@@ -0,0 +1,81 @@
+// Palsearch prints palindromes found in its input, one line at a time.
+//
+// Usage:
+//
+//	palsearch [-all] [-count] [-min n] [file ...]
+//
+// By default palsearch prints the longest palindrome on each line. With -all
+// it prints every maximal palindrome instead, and with -count it prints the
+// number of palindromic substrings on each line. In every mode palindromes
+// with fewer than -min letters are ignored. Palindromes follow the rules
+// of palindrome.IsPalindrome: case and non-letters are ignored. With no file
+// arguments palsearch reads standard input.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"gopl/ch11/palindrome"
+)
+
+var (
+	all        = flag.Bool("all", false, "print every maximal palindrome on each line")
+	count      = flag.Bool("count", false, "print the number of palindromic substrings on each line")
+	minLetters = flag.Int("min", 3, "ignore palindromes with fewer letters than this")
+)
+
+func main() {
+	flag.Parse()
+
+	files := flag.Args()
+	if len(files) == 0 {
+		if err := search(os.Stdout, "stdin", os.Stdin); err != nil {
+			fmt.Fprintf(os.Stderr, "palsearch: %v\n", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	status := 0
+	for _, name := range files {
+		f, err := os.Open(name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "palsearch: %v\n", err)
+			status = 1
+			continue
+		}
+		err = search(os.Stdout, name, f)
+		f.Close()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "palsearch: %s: %v\n", name, err)
+			status = 1
+		}
+	}
+	os.Exit(status)
+}
+
+// search scans r line by line and reports palindromes to out. Each report
+// starts with name, the line number and the rune column (both 1-based).
+func search(out io.Writer, name string, r io.Reader) error {
+	input := bufio.NewScanner(r)
+	for n := 1; input.Scan(); n++ {
+		line := input.Text()
+		switch {
+		case *count:
+			fmt.Fprintf(out, "%s:%d: %d\n", name, n, palindrome.CountMin(line, *minLetters))
+		case *all:
+			for _, m := range palindrome.Maximal(line, *minLetters) {
+				fmt.Fprintf(out, "%s:%d:%d: %q\n", name, n, m.RuneStart+1, m.Text)
+			}
+		default:
+			if m, ok := palindrome.Longest(line); ok && m.Letters >= *minLetters {
+				fmt.Fprintf(out, "%s:%d:%d: %q\n", name, n, m.RuneStart+1, m.Text)
+			}
+		}
+	}
+	return input.Err()
+}
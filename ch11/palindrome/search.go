@@ -0,0 +1,186 @@
+package palindrome
+
+import (
+	"sort"
+	"unicode"
+	"unicode/utf8"
+)
+
+// A Match is a palindromic substring of some text. Palindromes are found
+// using the same rules as IsPalindrome: letter case is ignored, as are
+// non-letters. A Match always begins and ends with a letter.
+type Match struct {
+	Text      string // the matching substring, exactly as it appears in the text
+	Start     int    // byte offset of the first letter
+	End       int    // byte offset just past the last letter
+	RuneStart int    // rune offset of the first letter
+	RuneEnd   int    // rune offset just past the last letter
+	Letters   int    // number of letters in the palindrome
+}
+
+// letters holds the normalized letters of a string together with the
+// position of each letter in the original string.
+type letters struct {
+	runes  []rune // lowercased letters
+	starts []int  // byte offset of each letter
+	widths []int  // encoded width of each letter
+	index  []int  // rune offset of each letter
+}
+
+// normalize extracts the letters of s, lowercased, and records where each one
+// appears in s.
+func normalize(s string) letters {
+	var ls letters
+	n := 0
+	for i, r := range s {
+		if unicode.IsLetter(r) {
+			ls.runes = append(ls.runes, unicode.ToLower(r))
+			ls.starts = append(ls.starts, i)
+			ls.widths = append(ls.widths, utf8.RuneLen(r))
+			ls.index = append(ls.index, n)
+		}
+		n++
+	}
+	return ls
+}
+
+// match returns the Match for the letters in the half-open range [i, j) of ls.
+func (ls letters) match(s string, i, j int) Match {
+	start := ls.starts[i]
+	end := ls.starts[j-1] + ls.widths[j-1]
+	return Match{
+		Text:      s[start:end],
+		Start:     start,
+		End:       end,
+		RuneStart: ls.index[i],
+		RuneEnd:   ls.index[j-1] + 1,
+		Letters:   j - i,
+	}
+}
+
+// manacher returns, for every letter i, the number of odd-length palindromes
+// centred on i (odd[i]) and the number of even-length palindromes whose right
+// half begins at i (even[i]). The work is linear in the number of letters.
+func manacher(rs []rune) (odd, even []int) {
+	n := len(rs)
+	odd = make([]int, n)
+	even = make([]int, n)
+
+	for i, l, r := 0, 0, -1; i < n; i++ {
+		k := 1
+		if i <= r {
+			k = odd[l+r-i]
+			if r-i+1 < k {
+				k = r - i + 1
+			}
+		}
+		for i-k >= 0 && i+k < n && rs[i-k] == rs[i+k] {
+			k++
+		}
+		odd[i] = k
+		if i+k-1 > r {
+			l, r = i-k+1, i+k-1
+		}
+	}
+
+	for i, l, r := 0, 0, -1; i < n; i++ {
+		k := 0
+		if i <= r {
+			k = even[l+r-i+1]
+			if r-i+1 < k {
+				k = r - i + 1
+			}
+		}
+		for i-k-1 >= 0 && i+k < n && rs[i-k-1] == rs[i+k] {
+			k++
+		}
+		even[i] = k
+		if i+k-1 > r {
+			l, r = i-k, i+k-1
+		}
+	}
+
+	return odd, even
+}
+
+// Longest returns the longest palindromic substring of s. If several
+// palindromes share the greatest length, Longest returns the leftmost one.
+// The boolean result is false if s contains no letters.
+func Longest(s string) (Match, bool) {
+	ls := normalize(s)
+	if len(ls.runes) == 0 {
+		return Match{}, false
+	}
+
+	odd, even := manacher(ls.runes)
+	bi, bj := 0, 1
+	for i := range ls.runes {
+		if lo, hi := i-odd[i]+1, i+odd[i]; hi-lo > bj-bi {
+			bi, bj = lo, hi
+		}
+		if lo, hi := i-even[i], i+even[i]; hi-lo > bj-bi {
+			bi, bj = lo, hi
+		}
+	}
+	return ls.match(s, bi, bj), true
+}
+
+// Maximal returns every maximal palindrome in s that has at least min letters.
+// A palindrome is maximal when it cannot be extended by one letter on each
+// side and remain a palindrome, so there is at most one maximal palindrome
+// for each centre. Matches are ordered by their position in s.
+func Maximal(s string, min int) []Match {
+	ls := normalize(s)
+	odd, even := manacher(ls.runes)
+
+	var ms []Match
+	for i := range ls.runes {
+		if n := 2 * even[i]; n > 0 && n >= min {
+			ms = append(ms, ls.match(s, i-even[i], i+even[i]))
+		}
+		if n := 2*odd[i] - 1; n >= min {
+			ms = append(ms, ls.match(s, i-odd[i]+1, i+odd[i]))
+		}
+	}
+	sort.Slice(ms, func(i, j int) bool {
+		if ms[i].Start != ms[j].Start {
+			return ms[i].Start < ms[j].Start
+		}
+		return ms[i].End < ms[j].End
+	})
+	return ms
+}
+
+// Count returns the number of palindromic substrings in s, counted by
+// position: the letters of "aaa" contain six palindromes ("a" three times,
+// "aa" twice and "aaa" once).
+func Count(s string) int {
+	return CountMin(s, 1)
+}
+
+// CountMin is like Count but counts only palindromes with at least min
+// letters: CountMin("aaa", 2) is 3.
+func CountMin(s string, min int) int {
+	ls := normalize(s)
+	odd, even := manacher(ls.runes)
+
+	// The palindromes centred on letter i have 1, 3, ..., 2*odd[i]-1 letters,
+	// and those whose right half begins at i have 2, 4, ..., 2*even[i].
+	oddMin, evenMin := (min+2)/2, (min+1)/2
+	if oddMin < 1 {
+		oddMin = 1
+	}
+	if evenMin < 1 {
+		evenMin = 1
+	}
+	n := 0
+	for i := range ls.runes {
+		if k := odd[i] - oddMin + 1; k > 0 {
+			n += k
+		}
+		if k := even[i] - evenMin + 1; k > 0 {
+			n += k
+		}
+	}
+	return n
+}
@@ -0,0 +1,132 @@
+package palindrome_test
+
+import (
+	"gopl/ch11/palindrome"
+	"testing"
+)
+
+func TestLongest(t *testing.T) {
+	var tests = []struct {
+		input string
+		want  string
+		ok    bool
+	}{
+		{"", "", false},
+		{"123 !?", "", false},
+		{"a", "a", true},
+		{"ab", "a", true},
+		{"abba", "abba", true},
+		{"xkayakx", "xkayakx", true},
+		{"the racecar is red", "racecar", true},
+		{"I said: A man, a plan, a canal: Panama!", "A man, a plan, a canal: Panama", true},
+		{"noon and deed", "noon", true}, // leftmost of equal length
+		{"Été", "Été", true},
+		{"xx 世界界世 y", "世界界世", true},
+	}
+	for _, test := range tests {
+		got, ok := palindrome.Longest(test.input)
+		if got.Text != test.want || ok != test.ok {
+			t.Errorf("Longest(%q) = %q, %v; want %q, %v",
+				test.input, got.Text, ok, test.want, test.ok)
+		}
+	}
+}
+
+func TestLongestOffsets(t *testing.T) {
+	input := "¡Ana, élé!"
+	m, _ := palindrome.Longest(input)
+	if m.Text != "Ana" {
+		t.Fatalf("Longest(%q).Text = %q, want %q", input, m.Text, "Ana")
+	}
+	if m.Start != 2 || m.End != 5 {
+		t.Errorf("Longest(%q) bytes = [%d, %d), want [2, 5)", input, m.Start, m.End)
+	}
+	if m.RuneStart != 1 || m.RuneEnd != 4 {
+		t.Errorf("Longest(%q) runes = [%d, %d), want [1, 4)", input, m.RuneStart, m.RuneEnd)
+	}
+	if input[m.Start:m.End] != m.Text {
+		t.Errorf("input[%d:%d] = %q, want %q", m.Start, m.End, input[m.Start:m.End], m.Text)
+	}
+}
+
+func TestMaximal(t *testing.T) {
+	var tests = []struct {
+		input string
+		min   int
+		want  []string
+	}{
+		{"", 1, nil},
+		{"abc", 1, []string{"a", "b", "c"}},
+		{"abc", 2, nil},
+		{"abba", 2, []string{"abba"}},
+		{"abacdc", 3, []string{"aba", "cdc"}},
+		{"Wow, a kayak!", 3, []string{"Wow", "a ka", "kayak"}},
+		{"aaa", 1, []string{"a", "aa", "aaa", "aa", "a"}},
+	}
+	for _, test := range tests {
+		ms := palindrome.Maximal(test.input, test.min)
+		var got []string
+		for _, m := range ms {
+			got = append(got, m.Text)
+		}
+		if !equal(got, test.want) {
+			t.Errorf("Maximal(%q, %d) = %q, want %q", test.input, test.min, got, test.want)
+		}
+	}
+}
+
+func TestCount(t *testing.T) {
+	var tests = []struct {
+		input string
+		want  int
+	}{
+		{"", 0},
+		{"a", 1},
+		{"ab", 2},
+		{"aaa", 6},
+		{"abba", 6},
+		{"A, a; A!", 6},
+		{"kayak", 7},
+	}
+	for _, test := range tests {
+		if got := palindrome.Count(test.input); got != test.want {
+			t.Errorf("Count(%q) = %d, want %d", test.input, got, test.want)
+		}
+	}
+}
+
+func TestCountMin(t *testing.T) {
+	var tests = []struct {
+		input string
+		min   int
+		want  int
+	}{
+		{"aaa", 0, 6},
+		{"aaa", 1, 6},
+		{"aaa", 2, 3},
+		{"aaa", 3, 1},
+		{"aaa", 4, 0},
+		{"abba", 2, 2},
+		{"abba", 3, 1},
+		{"kayak", 3, 2},
+		{"kayak", 5, 1},
+		{"A man, a plan, a canal: Panama", 21, 1},
+	}
+	for _, test := range tests {
+		if got := palindrome.CountMin(test.input, test.min); got != test.want {
+			t.Errorf("CountMin(%q, %d) = %d, want %d", test.input, test.min, got, test.want)
+		}
+	}
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,106 @@
+package palindrome
+
+import (
+	"unicode"
+
+	"github.com/rivo/uniseg"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// A Form selects the Unicode normalization applied to text before it is
+// checked for palindromes.
+type Form int
+
+const (
+	NoNorm Form = iota // leave text as it is
+	NFC                // canonical composition
+	NFD                // canonical decomposition
+)
+
+// Options configures IsPalindromeWith. The zero value gives the same results
+// as IsPalindrome.
+type Options struct {
+	// Form is the normalization applied before comparing. With NFC, letters
+	// written with combining accents compare equal to precomposed letters.
+	// With NFD and Graphemes unset, accents are dropped along with other
+	// non-letters, so "été" and "ete" compare equal.
+	Form Form
+
+	// Fold selects full Unicode case folding instead of unicode.ToLower. Full
+	// folding can change the length of text: "ß" folds to "ss".
+	Fold bool
+
+	// Graphemes compares extended grapheme clusters instead of runes, so a
+	// letter keeps its combining marks and an emoji sequence joined by ZWJ is
+	// treated as one unit. A cluster is compared if its first rune is a letter
+	// or an "other symbol" (category So, which includes emoji); any other
+	// cluster is ignored.
+	Graphemes bool
+}
+
+// IsPalindromeWith reports whether s reads the same forward and backward,
+// after applying the normalization, case folding and segmentation described
+// by opts. Folding does not preserve normalization, so s is normalized both
+// before and after it is folded, as in a canonical caseless match.
+func IsPalindromeWith(s string, opts Options) bool {
+	s = opts.Form.normalize(s)
+	if opts.Fold {
+		s = opts.Form.normalize(cases.Fold().String(s))
+	}
+
+	if !opts.Graphemes {
+		var letters []rune
+		for _, r := range s {
+			if !unicode.IsLetter(r) {
+				continue
+			}
+			if !opts.Fold {
+				r = unicode.ToLower(r)
+			}
+			letters = append(letters, r)
+		}
+		return isPalindromeRunes(letters)
+	}
+
+	var clusters []string
+	g := uniseg.NewGraphemes(s)
+	for g.Next() {
+		rs := g.Runes()
+		if !unicode.IsLetter(rs[0]) && !unicode.Is(unicode.So, rs[0]) {
+			continue
+		}
+		if !opts.Fold {
+			for i, r := range rs {
+				rs[i] = unicode.ToLower(r)
+			}
+		}
+		clusters = append(clusters, string(rs))
+	}
+	for i, j := 0, len(clusters)-1; i < j; i, j = i+1, j-1 {
+		if clusters[i] != clusters[j] {
+			return false
+		}
+	}
+	return true
+}
+
+// normalize returns s in form f.
+func (f Form) normalize(s string) string {
+	switch f {
+	case NFC:
+		return norm.NFC.String(s)
+	case NFD:
+		return norm.NFD.String(s)
+	}
+	return s
+}
+
+func isPalindromeRunes(rs []rune) bool {
+	for i, j := 0, len(rs)-1; i < j; i, j = i+1, j-1 {
+		if rs[i] != rs[j] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,92 @@
+package palindrome_test
+
+import (
+	"gopl/ch11/palindrome"
+	"testing"
+)
+
+func TestIsPalindromeWith(t *testing.T) {
+	var (
+		none      = palindrome.Options{}
+		nfc       = palindrome.Options{Form: palindrome.NFC}
+		nfd       = palindrome.Options{Form: palindrome.NFD}
+		fold      = palindrome.Options{Fold: true}
+		graphemes = palindrome.Options{Graphemes: true}
+		full      = palindrome.Options{Form: palindrome.NFC, Fold: true, Graphemes: true}
+		fullNFD   = palindrome.Options{Form: palindrome.NFD, Fold: true, Graphemes: true}
+	)
+	var tests = []struct {
+		input string
+		opts  palindrome.Options
+		want  bool
+	}{
+		// The zero Options behave like IsPalindrome.
+		{"", none, true},
+		{"A man, a plan, a canal: Panama", none, true},
+		{"palindrome", none, false},
+
+		// Latin with precomposed and combining accents.
+		{"été", none, true},
+		{"\u00e9te\u0301", none, false}, // mixed forms
+		{"\u00e9te\u0301", nfc, true},
+		{"\u00e9te\u0301", full, true},
+		{"e\u0301te\u0300", none, true},       // accents are dropped as non-letters
+		{"e\u0301te\u0300", graphemes, false}, // but not by graphemes
+		{"\u00e9t\u00e8", fullNFD, false},
+		{"\u00e9t\u00e8", nfd, true}, // NFD without graphemes strips accents
+
+		// Full case folding.
+		{"ßxSS", none, false},
+		{"ßxSS", fold, true},
+		{"ßxSS", full, true},
+
+		// Greek: final sigma folds to sigma.
+		{"Σας", none, false},
+		{"Σας", fold, true},
+		{"Νίψον ἀνομήματα, μὴ μόναν ὄψιν", full, false},
+
+		// ᾴ folds to ά and ι. Written with its marks out of canonical order,
+		// it folds to α and ί unless it is normalized first.
+		{"ι\u1fb4", full, true},
+		{"ι\u03b1\u0345\u0301", full, true},
+		{"ι\u03b1\u0345\u0301", fullNFD, true},
+		{"ι\u03b1\u0345\u0301", palindrome.Options{Form: palindrome.NFC, Fold: true}, true},
+
+		// Cyrillic.
+		{"А роза упала на лапу Азора", none, true},
+		{"А роза упала на лапу Азора", full, true},
+		{"Привет", full, false},
+
+		// Hebrew and Arabic.
+		{"אבא", none, true},
+		{"אבא", full, true},
+		{"سلس", full, true},
+
+		// Japanese kana and Han.
+		{"たけやぶやけた", none, true},
+		{"たけやぶやけた", full, true},
+		{"上海自来水来自海上", full, true},
+
+		// Korean: decomposed Hangul syllables only match as clusters.
+		{"기러기", nfd, false},
+		{"기러기", fullNFD, true},
+		{"기러기", full, true},
+
+		// Devanagari with vowel signs.
+		{"मलयालम", graphemes, true},
+		{"कमल", graphemes, false},
+
+		// Emoji sequences stay whole as graphemes.
+		{"👨‍👩‍👧 a 👨‍👩‍👧", graphemes, true},
+		{"👨‍👩‍👧 a 👧‍👩‍👨", graphemes, false},
+		{"🇫🇷x🇫🇷", graphemes, true},
+		{"🇫🇷x🇷🇫", graphemes, false},
+		{"👍🏽 👍🏽", graphemes, true},
+		{"👍🏽 🏽👍", graphemes, false},
+	}
+	for _, test := range tests {
+		if got := palindrome.IsPalindromeWith(test.input, test.opts); got != test.want {
+			t.Errorf("IsPalindromeWith(%q, %+v) = %v", test.input, test.opts, got)
+		}
+	}
+}